@@ -0,0 +1,53 @@
+package aws
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+type callerIdentity struct {
+	Account string
+}
+
+// AccountID returns the account the profile signs in to. It is read from the
+// profile's sso_account_id or role_arn when it has one, and otherwise asked
+// from sts with the aws cli.
+func (aws AWS) AccountID() (string, error) {
+	name := aws.profileName()
+	if name == "" {
+		name = "default"
+	}
+	if p, err := FindProfile(name); err == nil {
+		if p.SSOAccountID != "" {
+			return p.SSOAccountID, nil
+		}
+		if account, ok := roleAccount(p.RoleARN); ok {
+			return account, nil
+		}
+	}
+
+	cli, err := aws.FindCli()
+	if err != nil {
+		return "", err
+	}
+	out, err := cliBackend{aws: aws, cli: cli, profile: aws.profileName()}.exec("sts", "get-caller-identity", "--output", "json")
+	if err != nil {
+		return "", err
+	}
+
+	identity := callerIdentity{}
+	if err := json.Unmarshal([]byte(out), &identity); err != nil {
+		return "", err
+	}
+	return identity.Account, nil
+}
+
+// roleAccount returns the account of an IAM role ARN such as
+// arn:aws:iam::123456789012:role/admin.
+func roleAccount(arn string) (string, bool) {
+	parts := strings.SplitN(arn, ":", 6)
+	if len(parts) != 6 || parts[0] != "arn" || parts[2] != "iam" || parts[4] == "" {
+		return "", false
+	}
+	return parts[4], true
+}
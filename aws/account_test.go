@@ -0,0 +1,89 @@
+//go:build test
+// +build test
+
+package aws
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/eiladin/ekalias/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type AccountSuite struct {
+	suite.Suite
+}
+
+func TestAccountSuite(t *testing.T) {
+	suite.Run(t, new(AccountSuite))
+}
+
+func (suite AccountSuite) SetupTest() {
+	os.Setenv("AWS_CONFIG_FILE", filepath.Join("testdata", "config"))
+}
+
+func (suite AccountSuite) TearDownTest() {
+	os.Unsetenv("AWS_CONFIG_FILE")
+}
+
+func (suite AccountSuite) TestAccountIDFromConfig() {
+	cases := []struct {
+		profile  string
+		expected string
+	}{
+		{profile: "prod", expected: "222222222222"},
+		{profile: "legacy-sso", expected: "333333333333"},
+		{profile: "dev", expected: "111111111111"},
+	}
+
+	for _, c := range cases {
+		e := new(mocks.Executor)
+		res, err := New(e).WithOptions(Options{Profile: c.profile}).AccountID()
+		suite.NoError(err)
+		suite.Equal(c.expected, res)
+		e.AssertNotCalled(suite.T(), "FindExecutable", mock.Anything)
+	}
+}
+
+func (suite AccountSuite) TestAccountIDFromSTS() {
+	cases := []struct {
+		out      string
+		err      error
+		expected string
+	}{
+		{out: `{"UserId":"AIDA","Account":"444444444444","Arn":"arn:aws:iam::444444444444:user/me"}`, expected: "444444444444"},
+		{err: errors.New("sts failed")},
+		{out: "not json"},
+	}
+
+	for _, c := range cases {
+		e := new(mocks.Executor)
+		pe := new(mocks.Executor)
+		e.On("FindExecutable", executable).Return(executable, nil)
+		e.On("WithEnv", "AWS_PROFILE=default").Return(pe)
+		pe.On("ExecCommand", mock.Anything, executable, "sts", "get-caller-identity", "--output", "json", "--profile", "default").Return(c.out, c.err)
+
+		res, err := New(e).WithOptions(Options{Profile: "default"}).AccountID()
+		if c.expected == "" {
+			suite.Error(err)
+		} else {
+			suite.NoError(err)
+		}
+		suite.Equal(c.expected, res)
+	}
+}
+
+func (suite AccountSuite) TestRoleAccount() {
+	account, ok := roleAccount("arn:aws:iam::111111111111:role/dev")
+	suite.True(ok)
+	suite.Equal("111111111111", account)
+
+	for _, arn := range []string{"", "arn:aws:eks:us-east-1:111111111111:cluster/x", "arn:aws:iam:::role/dev", "role/dev"} {
+		_, ok := roleAccount(arn)
+		suite.False(ok, arn)
+	}
+}
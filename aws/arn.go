@@ -0,0 +1,27 @@
+package aws
+
+import "strings"
+
+type ClusterARN struct {
+	Partition string
+	Region    string
+	AccountID string
+	Name      string
+}
+
+func ParseClusterARN(arn string) (ClusterARN, bool) {
+	parts := strings.SplitN(arn, ":", 6)
+	if len(parts) != 6 || parts[0] != "arn" || parts[2] != "eks" || !strings.HasPrefix(parts[5], "cluster/") {
+		return ClusterARN{}, false
+	}
+	return ClusterARN{
+		Partition: parts[1],
+		Region:    parts[3],
+		AccountID: parts[4],
+		Name:      strings.TrimPrefix(parts[5], "cluster/"),
+	}, true
+}
+
+func (c ClusterARN) String() string {
+	return strings.Join([]string{"arn", c.Partition, "eks", c.Region, c.AccountID, "cluster/" + c.Name}, ":")
+}
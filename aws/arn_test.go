@@ -0,0 +1,48 @@
+//go:build test
+// +build test
+
+package aws
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ARNSuite struct {
+	suite.Suite
+}
+
+func TestARNSuite(t *testing.T) {
+	suite.Run(t, new(ARNSuite))
+}
+
+func (suite ARNSuite) TestParseClusterARN() {
+	cases := []struct {
+		arn      string
+		expected ClusterARN
+		ok       bool
+	}{
+		{
+			arn:      "arn:aws:eks:us-east-1:123456789012:cluster/prod",
+			expected: ClusterARN{Partition: "aws", Region: "us-east-1", AccountID: "123456789012", Name: "prod"},
+			ok:       true,
+		},
+		{
+			arn:      "arn:aws-us-gov:eks:us-gov-west-1:123456789012:cluster/gov",
+			expected: ClusterARN{Partition: "aws-us-gov", Region: "us-gov-west-1", AccountID: "123456789012", Name: "gov"},
+			ok:       true,
+		},
+		{arn: "minikube"},
+		{arn: "arn:aws:iam::123456789012:role/admin"},
+	}
+
+	for _, c := range cases {
+		res, ok := ParseClusterARN(c.arn)
+		suite.Equal(c.ok, ok, c.arn)
+		suite.Equal(c.expected, res, c.arn)
+		if ok {
+			suite.Equal(c.arn, res.String())
+		}
+	}
+}
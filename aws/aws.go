@@ -1,10 +1,11 @@
 package aws
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
-	"os"
+	"fmt"
 	"strings"
+	"time"
 
 	"github.com/eiladin/ekalias/console"
 )
@@ -14,13 +15,43 @@ const executable = "aws"
 var ErrProfileSpaces = errors.New("profile name cannot have spaces")
 var ErrProfileExists = errors.New("profile name already exists")
 var ErrNoClusters = errors.New("no clusters in selected account/region")
+var ErrProfileNotFound = errors.New("profile not found")
+var ErrClusterNotFound = errors.New("cluster not found in selected account/region")
+var ErrAmbiguousCluster = errors.New("cluster exists in more than one region, use region/cluster")
+
+// Options holds values that were provided up front and replace the matching prompts.
+type Options struct {
+	Profile        string
+	Region         string
+	Cluster        string
+	KubeAlias      string
+	NonInteractive bool
+	Backend        string
+	Endpoint       string
+	Workers        int
+	// Timeout limits each request of the api backend
+	Timeout time.Duration
+}
 
 type AWS struct {
 	executor console.Executor
+	opts     Options
+	ctx      context.Context
 }
 
 func New(e console.Executor) AWS {
-	return AWS{executor: e}
+	return AWS{executor: e, ctx: context.Background()}
+}
+
+func (aws AWS) WithOptions(o Options) AWS {
+	aws.opts = o
+	return aws
+}
+
+// WithContext returns a copy of aws whose commands are stopped when ctx is done.
+func (aws AWS) WithContext(ctx context.Context) AWS {
+	aws.ctx = ctx
+	return aws
 }
 
 func (aws AWS) FindCli() (string, error) {
@@ -28,12 +59,20 @@ func (aws AWS) FindCli() (string, error) {
 }
 
 func (aws AWS) findProfiles() ([]string, error) {
+	if profiles, err := LoadProfiles(); err == nil && len(profiles) > 0 {
+		names := make([]string, len(profiles))
+		for i, p := range profiles {
+			names[i] = p.Name
+		}
+		return names, nil
+	}
+
 	cli, err := aws.FindCli()
 	if err != nil {
 		return []string{}, err
 	}
 
-	out, err := aws.executor.ExecCommand(cli, "configure", "list-profiles")
+	out, err := aws.executor.ExecCommand(aws.ctx, cli, "configure", "list-profiles")
 	if err != nil {
 		return []string{}, err
 	}
@@ -47,12 +86,15 @@ func (aws AWS) profileExists(newProfile string) bool {
 		return false
 	}
 
-	for _, prof := range profs {
-		if prof == newProfile {
+	return contains(profs, newProfile)
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
 			return true
 		}
 	}
-
 	return false
 }
 
@@ -92,7 +134,7 @@ func (aws AWS) CreateProfile() (string, error) {
 		args = append(args, "sso")
 	}
 
-	err = aws.executor.ExecInteractive(cli, args...)
+	err = aws.executor.ExecInteractive(aws.ctx, cli, args...)
 	if err != nil {
 		return "", err
 	}
@@ -100,73 +142,101 @@ func (aws AWS) CreateProfile() (string, error) {
 	return newProfile, nil
 }
 
-type clusterlist struct {
-	Clusters []string
-}
-
 func (aws AWS) CreateKubeContext() (string, error) {
-	var context string
-	var region string
-
-	cli, err := aws.FindCli()
+	backend, err := aws.clusterBackend()
 	if err != nil {
 		return "", err
 	}
 
-	region, err = aws.executor.PromptInput("AWS Region: ")
-	if err != nil {
+	if err := aws.ensureSSOSession(); err != nil {
 		return "", err
 	}
 
-	out, err := aws.executor.ExecCommand(cli, "eks", "list-clusters", "--region", region)
+	region, err := aws.selectRegion()
 	if err != nil {
 		return "", err
 	}
 
-	cl := clusterlist{}
-	err = json.Unmarshal([]byte(out), &cl)
-	if err != nil {
-		return "", err
+	var clusters []clusterRef
+	if region == AllRegions {
+		regions, err := backend.Regions()
+		if err != nil {
+			return "", err
+		}
+		clusters, err = aws.scanRegions(backend, regions)
+		if err != nil {
+			return "", err
+		}
+	} else {
+		names, err := backend.ListClusters(region)
+		if err != nil {
+			return "", err
+		}
+		for _, name := range names {
+			clusters = append(clusters, clusterRef{region: region, name: name})
+		}
 	}
-	if len(cl.Clusters) == 0 {
+	if len(clusters) == 0 {
 		return "", ErrNoClusters
 	}
 
-	for context == "" {
-		context, err = aws.executor.SelectValueFromList(cl.Clusters, "Cluster", nil)
+	labels := make([]string, len(clusters))
+	byLabel := map[string]clusterRef{}
+	for i, c := range clusters {
+		labels[i] = c.name
+		if region == AllRegions {
+			labels[i] = c.String()
+		}
+		byLabel[labels[i]] = c
+	}
+
+	var selected clusterRef
+	switch {
+	case aws.opts.Cluster != "":
+		selected, err = findCluster(clusters, aws.opts.Cluster)
 		if err != nil {
 			return "", err
 		}
+	case aws.opts.NonInteractive:
+		return "", fmt.Errorf("%w: use --cluster", console.ErrNonInteractive)
 	}
 
-	alias, err := aws.executor.PromptInput("Kube Context Alias: ")
-	if err != nil {
-		return "", err
+	for selected.name == "" {
+		label, err := aws.executor.SelectValueFromList(labels, "Cluster", nil)
+		if err != nil {
+			return "", err
+		}
+		selected = byLabel[label]
 	}
 
-	args := []string{"eks", "update-kubeconfig", "--region", region, "--name", context}
-	var contextName string
-
-	if len(alias) > 0 {
-		contextName = alias
-		args = append(args, "--alias", alias)
+	alias := aws.opts.KubeAlias
+	if alias == "" && !aws.opts.NonInteractive {
+		alias, err = aws.executor.PromptInput("Kube Context Alias: ")
+		if err != nil {
+			return "", err
+		}
 	}
 
-	out, err = aws.executor.ExecCommand(cli, args...)
-	if err != nil {
-		return "", err
-	}
+	return backend.UpdateKubeconfig(selected.region, selected.name, alias)
+}
 
-	if contextName == "" {
-		s := strings.Split(out, " ")
-		for _, item := range s {
-			if strings.HasPrefix(item, "arn:aws") {
-				contextName = item
-			}
+// findCluster matches name against "region/cluster" or a cluster name that
+// is unique across the scanned regions.
+func findCluster(clusters []clusterRef, name string) (clusterRef, error) {
+	var found []clusterRef
+	for _, c := range clusters {
+		if c.String() == name || c.name == name {
+			found = append(found, c)
 		}
 	}
-
-	return contextName, nil
+	switch len(found) {
+	case 0:
+		return clusterRef{}, fmt.Errorf("%w: %s", ErrClusterNotFound, name)
+	case 1:
+		return found[0], nil
+	default:
+		return clusterRef{}, fmt.Errorf("%w: %s", ErrAmbiguousCluster, name)
+	}
 }
 
 func (aws AWS) SelectProfile() (string, error) {
@@ -175,11 +245,21 @@ func (aws AWS) SelectProfile() (string, error) {
 		return "", err
 	}
 
-	selectedProfile, err := aws.executor.SelectValueFromList(awsprofiles, "AWS Profile", aws.CreateProfile)
-	if err != nil {
-		return "", err
+	var selectedProfile string
+	switch {
+	case aws.opts.Profile != "":
+		if !contains(awsprofiles, aws.opts.Profile) {
+			return "", fmt.Errorf("%w: %s", ErrProfileNotFound, aws.opts.Profile)
+		}
+		selectedProfile = aws.opts.Profile
+	case aws.opts.NonInteractive:
+		return "", fmt.Errorf("%w: use --profile", console.ErrNonInteractive)
+	default:
+		selectedProfile, err = aws.executor.SelectValueFromList(awsprofiles, "AWS Profile", aws.CreateProfile)
+		if err != nil {
+			return "", err
+		}
 	}
 
-	os.Setenv("AWS_PROFILE", selectedProfile)
 	return selectedProfile, nil
 }
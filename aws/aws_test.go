@@ -7,8 +7,10 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/eiladin/ekalias/console"
 	"github.com/eiladin/ekalias/mocks"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
@@ -22,6 +24,16 @@ func TestAWSSuite(t *testing.T) {
 	suite.Run(t, new(AWSSuite))
 }
 
+func (suite AWSSuite) SetupTest() {
+	os.Setenv("AWS_CONFIG_FILE", filepath.Join("testdata", "missing"))
+	os.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join("testdata", "missing"))
+}
+
+func (suite AWSSuite) TearDownTest() {
+	os.Unsetenv("AWS_CONFIG_FILE")
+	os.Unsetenv("AWS_SHARED_CREDENTIALS_FILE")
+}
+
 func (suite AWSSuite) TestNew() {
 	e := new(mocks.Executor)
 	aws := New(e)
@@ -54,7 +66,7 @@ func (suite AWSSuite) TestFindProfiles() {
 	for _, c := range cases {
 		e := new(mocks.Executor)
 		e.On("FindExecutable", executable).Return(c.findExecutableResult, c.findExecutableError)
-		e.On("ExecCommand", executable, "configure", "list-profiles").Return(c.execCommandResult, c.execCommandError)
+		e.On("ExecCommand", mock.Anything, executable, "configure", "list-profiles").Return(c.execCommandResult, c.execCommandError)
 		a := New(e)
 		res, err := a.findProfiles()
 		if c.expectedError {
@@ -68,7 +80,7 @@ func (suite AWSSuite) TestFindProfiles() {
 
 func (suite AWSSuite) TestProfileExists() {
 	e := new(mocks.Executor)
-	e.On("ExecCommand", executable, "configure", "list-profiles").Return("a\nb\nc", nil)
+	e.On("ExecCommand", mock.Anything, executable, "configure", "list-profiles").Return("a\nb\nc", nil)
 	e.On("FindExecutable", executable).Return(executable, nil)
 	a := New(e)
 
@@ -118,9 +130,9 @@ func (suite AWSSuite) TestCreateProfile() {
 	for _, c := range cases {
 		e := mocks.Executor{}
 		e.On("FindExecutable", executable).Return(executable, c.findExecutableError)
-		e.On("ExecCommand", executable, "configure", "list-profiles").Return(c.existingProfiles, c.execCommandError)
-		e.On("ExecInteractive", executable, "configure", "--profile", c.newProfile).Return(c.execInteractiveError)
-		e.On("ExecInteractive", executable, "configure", "--profile", c.newProfile, "sso").Return(c.execInteractiveError)
+		e.On("ExecCommand", mock.Anything, executable, "configure", "list-profiles").Return(c.existingProfiles, c.execCommandError)
+		e.On("ExecInteractive", mock.Anything, executable, "configure", "--profile", c.newProfile).Return(c.execInteractiveError)
+		e.On("ExecInteractive", mock.Anything, executable, "configure", "--profile", c.newProfile, "sso").Return(c.execInteractiveError)
 		if c.sso {
 			e.On("PromptInput", "Use SSO? (only 'yes' will be accepted to approve): ").Return("yes", c.ssoError)
 		} else {
@@ -190,6 +202,7 @@ func (suite AWSSuite) TestCreateKubeContext() {
 			expectedError:  true,
 		},
 		{
+			region:            "us-east-1",
 			listClustersError: errors.New("list clusters"),
 			expectedError:     true,
 		},
@@ -235,11 +248,11 @@ func (suite AWSSuite) TestCreateKubeContext() {
 		e := new(mocks.Executor)
 		fullClusterName := fmt.Sprintf("arn:aws:eks:%s:accountID:cluster/%s", c.region, c.selectedClusterName)
 		e.On("FindExecutable", executable).Return(executable, c.findExecutableError)
-		e.On("PromptInput", "AWS Region: ").Return(c.region, c.regionError)
+		e.On("SelectValueFromList", mock.Anything, "AWS Region", mock.Anything).Return(c.region, c.regionError)
 		e.On("PromptInput", "Kube Context Alias: ").Return(c.alias, c.aliasError)
-		e.On("ExecCommand", executable, "eks", "list-clusters", "--region", c.region).Return(c.clusterlist, c.listClustersError)
-		e.On("ExecCommand", executable, "eks", "update-kubeconfig", "--region", c.region, "--name", c.selectedClusterName).Return(fmt.Sprintf("Updated context %s in /home/user/.kube/config", fullClusterName), c.updateConfigError)
-		e.On("ExecCommand", executable, "eks", "update-kubeconfig", "--region", c.region, "--name", c.selectedClusterName, "--alias", c.alias).Return(fmt.Sprintf("Updated context %s in /home/user/.kube/config", c.alias), c.updateConfigError)
+		e.On("ExecCommand", mock.Anything, executable, "eks", "list-clusters", "--region", c.region).Return(c.clusterlist, c.listClustersError)
+		e.On("ExecCommand", mock.Anything, executable, "eks", "update-kubeconfig", "--region", c.region, "--name", c.selectedClusterName).Return(fmt.Sprintf("Updated context %s in /home/user/.kube/config", fullClusterName), c.updateConfigError)
+		e.On("ExecCommand", mock.Anything, executable, "eks", "update-kubeconfig", "--region", c.region, "--name", c.selectedClusterName, "--alias", c.alias).Return(fmt.Sprintf("Updated context %s in /home/user/.kube/config", c.alias), c.updateConfigError)
 		e.On("SelectValueFromList", c.selectList, "Cluster", mock.Anything).Return(c.selectedClusterName, c.selectClusterError)
 		a := New(e)
 
@@ -270,7 +283,7 @@ func (suite AWSSuite) TestSelectProfile() {
 	for _, c := range cases {
 		e := new(mocks.Executor)
 		e.On("FindExecutable", executable).Return(executable, c.findProfilesError)
-		e.On("ExecCommand", executable, "configure", "list-profiles").Return("a\nb\nc", c.listProfilesError)
+		e.On("ExecCommand", mock.Anything, executable, "configure", "list-profiles").Return("a\nb\nc", c.listProfilesError)
 		e.On("ReadInput").Return("1", c.selectProfileError)
 		e.On("SelectValueFromList", []string{"a", "b", "c"}, "AWS Profile", mock.Anything).Return("a", c.selectProfileError)
 		a := New(e)
@@ -282,7 +295,116 @@ func (suite AWSSuite) TestSelectProfile() {
 			suite.NoError(err)
 		}
 		suite.Equal(c.expectedResult, res)
-		suite.Equal(c.expectedResult, os.Getenv("AWS_PROFILE"))
+		// the profile is passed to commands explicitly, not through our own environment
+		suite.Empty(os.Getenv("AWS_PROFILE"))
+	}
+}
+
+func (suite AWSSuite) TestSelectProfileWithOptions() {
+	cases := []struct {
+		opts           Options
+		expectedResult string
+		expectedError  error
+	}{
+		{opts: Options{Profile: "b"}, expectedResult: "b"},
+		{opts: Options{Profile: "b", NonInteractive: true}, expectedResult: "b"},
+		{opts: Options{Profile: "d"}, expectedError: ErrProfileNotFound},
+		{opts: Options{NonInteractive: true}, expectedError: console.ErrNonInteractive},
+	}
+
+	for _, c := range cases {
+		e := new(mocks.Executor)
+		e.On("FindExecutable", executable).Return(executable, nil)
+		e.On("ExecCommand", mock.Anything, executable, "configure", "list-profiles").Return("a\nb\nc", nil)
+		a := New(e).WithOptions(c.opts)
+
+		res, err := a.SelectProfile()
+		if c.expectedError != nil {
+			suite.True(errors.Is(err, c.expectedError))
+		} else {
+			suite.NoError(err)
+		}
+		suite.Equal(c.expectedResult, res)
+		e.AssertNotCalled(suite.T(), "SelectValueFromList", mock.Anything, mock.Anything, mock.Anything)
 		os.Unsetenv("AWS_PROFILE")
 	}
 }
+
+func (suite AWSSuite) TestCreateKubeContextWithOptions() {
+	cases := []struct {
+		opts           Options
+		expectedResult string
+		expectedError  error
+	}{
+		{opts: Options{Region: "us-east-1", Cluster: "a", KubeAlias: "newalias"}, expectedResult: "newalias"},
+		{opts: Options{Region: "us-east-1", Cluster: "b", NonInteractive: true}, expectedResult: "arn:aws:eks:us-east-1:accountID:cluster/b"},
+		{opts: Options{Region: "us-east-1", Cluster: "c"}, expectedError: ErrClusterNotFound},
+		{opts: Options{NonInteractive: true}, expectedError: console.ErrNonInteractive},
+		{opts: Options{Region: "us-east-1", NonInteractive: true}, expectedError: console.ErrNonInteractive},
+	}
+
+	for _, c := range cases {
+		e := new(mocks.Executor)
+		e.On("FindExecutable", executable).Return(executable, nil)
+		e.On("ExecCommand", mock.Anything, executable, "eks", "list-clusters", "--region", "us-east-1").Return(`{"clusters":["a","b"]}`, nil)
+		e.On("ExecCommand", mock.Anything, executable, "eks", "update-kubeconfig", "--region", "us-east-1", "--name", c.opts.Cluster).Return(fmt.Sprintf("Updated context arn:aws:eks:us-east-1:accountID:cluster/%s in /home/user/.kube/config", c.opts.Cluster), nil)
+		e.On("ExecCommand", mock.Anything, executable, "eks", "update-kubeconfig", "--region", "us-east-1", "--name", c.opts.Cluster, "--alias", c.opts.KubeAlias).Return(fmt.Sprintf("Updated context %s in /home/user/.kube/config", c.opts.KubeAlias), nil)
+		a := New(e).WithOptions(c.opts)
+
+		res, err := a.CreateKubeContext()
+		if c.expectedError != nil {
+			suite.True(errors.Is(err, c.expectedError))
+		} else {
+			suite.NoError(err)
+		}
+		suite.Equal(c.expectedResult, res)
+		e.AssertNotCalled(suite.T(), "PromptInput", mock.Anything)
+		e.AssertNotCalled(suite.T(), "SelectValueFromList", mock.Anything, mock.Anything, mock.Anything)
+	}
+}
+
+func (suite AWSSuite) TestCreateKubeContextWithProfile() {
+	e := new(mocks.Executor)
+	pe := new(mocks.Executor)
+	e.On("FindExecutable", executable).Return(executable, nil)
+	e.On("WithEnv", "AWS_PROFILE=dev").Return(pe)
+	e.On("SelectValueFromList", []string{"a", "b"}, "Cluster", mock.Anything).Return("b", nil)
+	e.On("PromptInput", "Kube Context Alias: ").Return("dev-b", nil)
+	pe.On("ExecCommand", mock.Anything, executable, "eks", "list-clusters", "--region", "us-east-1", "--profile", "dev").Return(`{"clusters":["a","b"]}`, nil)
+	pe.On("ExecCommand", mock.Anything, executable, "eks", "update-kubeconfig", "--region", "us-east-1", "--name", "b", "--alias", "dev-b", "--profile", "dev").Return("Updated context dev-b in /home/user/.kube/config", nil)
+
+	res, err := New(e).WithOptions(Options{Profile: "dev", Region: "us-east-1"}).CreateKubeContext()
+	suite.NoError(err)
+	suite.Equal("dev-b", res)
+	e.AssertNotCalled(suite.T(), "ExecCommand", mock.Anything, mock.Anything, mock.Anything)
+	pe.AssertExpectations(suite.T())
+}
+
+func (suite AWSSuite) TestCreateKubeContextWithProfileAllRegions() {
+	e := new(mocks.Executor)
+	pe := new(mocks.Executor)
+	e.On("FindExecutable", executable).Return(executable, nil)
+	e.On("WithEnv", "AWS_PROFILE=dev").Return(pe)
+	pe.On("ExecCommand", mock.Anything, executable, "ec2", "describe-regions", "--output", "json", "--region", "us-east-1", "--profile", "dev").Return(`{"Regions":[{"RegionName":"us-east-1"},{"RegionName":"us-west-2"}]}`, nil)
+	pe.On("ExecCommand", mock.Anything, executable, "eks", "list-clusters", "--region", "us-east-1", "--profile", "dev").Return(`{"clusters":[]}`, nil)
+	pe.On("ExecCommand", mock.Anything, executable, "eks", "list-clusters", "--region", "us-west-2", "--profile", "dev").Return(`{"clusters":["b"]}`, nil)
+	pe.On("ExecCommand", mock.Anything, executable, "eks", "update-kubeconfig", "--region", "us-west-2", "--name", "b", "--profile", "dev").Return("Updated context arn:aws:eks:us-west-2:accountID:cluster/b in /home/user/.kube/config", nil)
+
+	res, err := New(e).WithOptions(Options{Profile: "dev", Region: AllRegions, Cluster: "b", NonInteractive: true}).CreateKubeContext()
+	suite.NoError(err)
+	suite.Equal("arn:aws:eks:us-west-2:accountID:cluster/b", res)
+	e.AssertNotCalled(suite.T(), "ExecCommand", mock.Anything, mock.Anything, mock.Anything)
+	pe.AssertExpectations(suite.T())
+}
+
+func (suite AWSSuite) TestFindProfilesFromConfig() {
+	os.Setenv("AWS_CONFIG_FILE", filepath.Join("testdata", "config"))
+	os.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join("testdata", "credentials"))
+
+	e := new(mocks.Executor)
+	a := New(e)
+	res, err := a.findProfiles()
+	suite.NoError(err)
+	suite.Equal([]string{"default", "dev", "prod", "legacy-sso", "ci"}, res)
+	e.AssertNotCalled(suite.T(), "FindExecutable", executable)
+}
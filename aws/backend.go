@@ -0,0 +1,220 @@
+package aws
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/eiladin/ekalias/eks"
+	"github.com/eiladin/ekalias/kubeconfig"
+)
+
+const (
+	BackendCLI = "cli"
+	BackendAPI = "api"
+)
+
+var ErrUnknownBackend = errors.New("unknown backend")
+
+// ClusterBackend discovers EKS clusters and writes kubeconfig entries for them.
+type ClusterBackend interface {
+	Regions() ([]string, error)
+	ListClusters(region string) ([]string, error)
+	UpdateKubeconfig(region, cluster, alias string) (string, error)
+}
+
+func (aws AWS) clusterBackend() (ClusterBackend, error) {
+	return aws.backendFor(aws.profileName())
+}
+
+// backendFor returns a backend that runs every call under profile, so
+// several profiles can be used at once.
+func (aws AWS) backendFor(profile string) (ClusterBackend, error) {
+	switch aws.opts.Backend {
+	case "", BackendCLI:
+		cli, err := aws.FindCli()
+		if err != nil {
+			return nil, err
+		}
+		return cliBackend{aws: aws, cli: cli, profile: profile}, nil
+	case BackendAPI:
+		return apiBackend{aws: aws, endpoint: aws.opts.Endpoint, profile: profile}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s (supported: %s, %s)", ErrUnknownBackend, aws.opts.Backend, BackendCLI, BackendAPI)
+	}
+}
+
+type cliBackend struct {
+	aws     AWS
+	cli     string
+	profile string
+}
+
+type clusterlist struct {
+	Clusters []string
+}
+
+type regionlist struct {
+	Regions []struct {
+		RegionName string
+	}
+}
+
+// exec runs the aws cli under the backend's profile, passing it both as
+// --profile and AWS_PROFILE when there is one.
+func (b cliBackend) exec(args ...string) (string, error) {
+	action := "aws " + strings.Join(args[:2], " ")
+	e := b.aws.executor
+	if b.profile != "" {
+		args = append(args, "--profile", b.profile)
+		e = e.WithEnv("AWS_PROFILE=" + b.profile)
+	}
+	out, err := e.ExecCommand(b.aws.ctx, b.cli, args...)
+	return out, explain(b.profile, action, err)
+}
+
+func (b cliBackend) Regions() ([]string, error) {
+	args := []string{"ec2", "describe-regions", "--output", "json"}
+	if b.profile != "" {
+		// describe-regions fails for profiles without a region
+		args = append(args, "--region", profileRegion(b.profile))
+	}
+	out, err := b.exec(args...)
+	if err != nil {
+		return nil, err
+	}
+
+	rl := regionlist{}
+	if err := json.Unmarshal([]byte(out), &rl); err != nil {
+		return nil, err
+	}
+	regions := make([]string, len(rl.Regions))
+	for i, r := range rl.Regions {
+		regions[i] = r.RegionName
+	}
+	return regions, nil
+}
+
+func (b cliBackend) ListClusters(region string) ([]string, error) {
+	out, err := b.exec("eks", "list-clusters", "--region", region)
+	if err != nil {
+		return nil, err
+	}
+
+	cl := clusterlist{}
+	err = json.Unmarshal([]byte(out), &cl)
+	if err != nil {
+		return nil, err
+	}
+	return cl.Clusters, nil
+}
+
+func (b cliBackend) UpdateKubeconfig(region, cluster, alias string) (string, error) {
+	args := []string{"eks", "update-kubeconfig", "--region", region, "--name", cluster}
+	var contextName string
+
+	if len(alias) > 0 {
+		contextName = alias
+		args = append(args, "--alias", alias)
+	}
+
+	out, err := b.exec(args...)
+	if err != nil {
+		return "", err
+	}
+
+	if contextName == "" {
+		s := strings.Split(out, " ")
+		for _, item := range s {
+			if strings.HasPrefix(item, "arn:aws") {
+				contextName = item
+			}
+		}
+	}
+
+	return contextName, nil
+}
+
+type apiBackend struct {
+	aws      AWS
+	endpoint string
+	profile  string
+}
+
+func (b apiBackend) client(region string) (eks.Client, error) {
+	creds, err := ResolveCredentials(b.profile)
+	if err != nil {
+		return eks.Client{}, err
+	}
+	c := eks.Client{Region: region, Credentials: creds, Endpoint: b.endpoint, Timeout: b.aws.opts.Timeout}
+	return c.WithContext(b.aws.ctx), nil
+}
+
+// profileRegion returns the region configured for profile, or us-east-1.
+func profileRegion(profile string) string {
+	if p, err := FindProfile(profile); err == nil && p.Region != "" {
+		return p.Region
+	}
+	return "us-east-1"
+}
+
+func (b apiBackend) Regions() ([]string, error) {
+	c, err := b.client(profileRegion(b.profile))
+	if err != nil {
+		return nil, err
+	}
+	regions, err := c.Regions()
+	return regions, explain(b.profile, "ec2:DescribeRegions", err)
+}
+
+func (b apiBackend) ListClusters(region string) ([]string, error) {
+	c, err := b.client(region)
+	if err != nil {
+		return nil, err
+	}
+	clusters, err := c.ListClusters()
+	return clusters, explain(b.profile, "eks:ListClusters", err)
+}
+
+func (b apiBackend) UpdateKubeconfig(region, cluster, alias string) (string, error) {
+	c, err := b.client(region)
+	if err != nil {
+		return "", err
+	}
+	info, err := c.DescribeCluster(cluster)
+	if err != nil {
+		return "", explain(b.profile, "eks:DescribeCluster", err)
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+
+	contextName := info.Arn
+	if alias != "" {
+		contextName = alias
+	}
+
+	entry := kubeconfig.Entry{
+		Context: contextName,
+		Cluster: info.Arn,
+		Server:  info.Endpoint,
+		CAData:  info.CertificateAuthority.Data,
+		User:    info.Arn,
+		Exec: kubeconfig.Exec{
+			Command: exe,
+			Args:    []string{"token", "--cluster-name", cluster, "--region", region},
+		},
+	}
+	if b.profile != "" {
+		entry.Exec.Env = map[string]string{"AWS_PROFILE": b.profile}
+	}
+
+	if err := kubeconfig.Upsert(kubeconfig.Files()[0], entry); err != nil {
+		return "", err
+	}
+	return contextName, nil
+}
@@ -0,0 +1,94 @@
+//go:build test
+// +build test
+
+package aws
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/eiladin/ekalias/kubeconfig"
+	"github.com/eiladin/ekalias/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type BackendSuite struct {
+	suite.Suite
+}
+
+func TestBackendSuite(t *testing.T) {
+	suite.Run(t, new(BackendSuite))
+}
+
+func (suite BackendSuite) TearDownTest() {
+	for _, env := range []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "KUBECONFIG"} {
+		os.Unsetenv(env)
+	}
+}
+
+func (suite BackendSuite) TestClusterBackend() {
+	e := new(mocks.Executor)
+	e.On("FindExecutable", executable).Return(executable, nil)
+
+	b, err := New(e).clusterBackend()
+	suite.NoError(err)
+	suite.IsType(cliBackend{}, b)
+
+	b, err = New(e).WithOptions(Options{Backend: BackendAPI, Endpoint: "http://localhost"}).clusterBackend()
+	suite.NoError(err)
+	suite.Equal("http://localhost", b.(apiBackend).endpoint)
+
+	_, err = New(e).WithOptions(Options{Backend: "sdk"}).clusterBackend()
+	suite.True(errors.Is(err, ErrUnknownBackend))
+
+	e = new(mocks.Executor)
+	e.On("FindExecutable", executable).Return("", errors.New("not found"))
+	_, err = New(e).clusterBackend()
+	suite.Error(err)
+}
+
+func (suite BackendSuite) TestAPIBackend() {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/clusters":
+			w.Write([]byte(`{"clusters":["prod","dev"]}`))
+		case "/clusters/prod":
+			w.Write([]byte(`{"cluster":{"name":"prod","arn":"arn:aws:eks:us-east-1:123456789012:cluster/prod","endpoint":"https://ABC.gr7.us-east-1.eks.amazonaws.com","certificateAuthority":{"data":"Y2E="}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	dir, err := ioutil.TempDir("", "ekalias")
+	suite.Require().NoError(err)
+	defer os.RemoveAll(dir)
+	os.Setenv("KUBECONFIG", filepath.Join(dir, "config"))
+	os.Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+	os.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+
+	e := new(mocks.Executor)
+	e.On("PromptInput", "Kube Context Alias: ").Return("prod", nil)
+	e.On("SelectValueFromList", []string{"prod", "dev"}, "Cluster", mock.Anything).Return("prod", nil)
+	a := New(e).WithOptions(Options{Backend: BackendAPI, Endpoint: srv.URL, Region: "us-east-1"})
+
+	res, err := a.CreateKubeContext()
+	suite.NoError(err)
+	suite.Equal("prod", res)
+	e.AssertNotCalled(suite.T(), "FindExecutable", executable)
+	e.AssertNotCalled(suite.T(), "ExecCommand", mock.Anything, mock.Anything)
+
+	c, err := kubeconfig.Find("prod")
+	suite.NoError(err)
+	suite.Equal("arn:aws:eks:us-east-1:123456789012:cluster/prod", c.Cluster)
+	suite.Equal("https://ABC.gr7.us-east-1.eks.amazonaws.com", c.Server)
+
+	_, err = apiBackend{endpoint: srv.URL}.UpdateKubeconfig("us-east-1", "missing", "")
+	suite.Error(err)
+}
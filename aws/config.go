@@ -0,0 +1,172 @@
+package aws
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var ErrNoConfig = errors.New("no aws config or credentials file found")
+
+type Profile struct {
+	Name          string
+	Region        string
+	SSOSession    string
+	SSOStartURL   string
+	SSORegion     string
+	SSOAccountID  string
+	SSORoleName   string
+	RoleARN       string
+	SourceProfile string
+}
+
+type section struct {
+	name   string
+	values map[string]string
+}
+
+// ConfigFile returns the path of the shared config file, honouring AWS_CONFIG_FILE.
+func ConfigFile() string {
+	return awsFile("AWS_CONFIG_FILE", "config")
+}
+
+// CredentialsFile returns the path of the shared credentials file, honouring AWS_SHARED_CREDENTIALS_FILE.
+func CredentialsFile() string {
+	return awsFile("AWS_SHARED_CREDENTIALS_FILE", "credentials")
+}
+
+func awsFile(env, name string) string {
+	home, _ := os.UserHomeDir()
+	if p := os.Getenv(env); p != "" {
+		if strings.HasPrefix(p, "~/") {
+			return filepath.Join(home, p[2:])
+		}
+		return p
+	}
+	return filepath.Join(home, ".aws", name)
+}
+
+func parseINI(r io.Reader) ([]section, error) {
+	var sections []section
+	var current *section
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		raw := scanner.Text()
+		line := strings.TrimSpace(raw)
+		switch {
+		case line == "", strings.HasPrefix(line, "#"), strings.HasPrefix(line, ";"):
+			continue
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			sections = append(sections, section{
+				name:   strings.TrimSpace(line[1 : len(line)-1]),
+				values: map[string]string{},
+			})
+			current = &sections[len(sections)-1]
+		case current == nil, raw[0] == ' ', raw[0] == '\t':
+			// values before the first section and nested values (s3 = ...) are not used
+			continue
+		default:
+			parts := strings.SplitN(line, "=", 2)
+			if len(parts) != 2 {
+				continue
+			}
+			current.values[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+		}
+	}
+	return sections, scanner.Err()
+}
+
+func readINI(path string) ([]section, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return parseINI(f)
+}
+
+// LoadProfiles reads every profile from the shared config and credentials files.
+func LoadProfiles() ([]Profile, error) {
+	config, configErr := readINI(ConfigFile())
+	if configErr != nil && !os.IsNotExist(configErr) {
+		return nil, configErr
+	}
+	credentials, credentialsErr := readINI(CredentialsFile())
+	if credentialsErr != nil && !os.IsNotExist(credentialsErr) {
+		return nil, credentialsErr
+	}
+	if configErr != nil && credentialsErr != nil {
+		return nil, ErrNoConfig
+	}
+
+	sessions := map[string]map[string]string{}
+	var profiles []Profile
+	seen := map[string]bool{}
+	for _, s := range config {
+		name := s.name
+		switch {
+		case strings.HasPrefix(name, "sso-session "):
+			sessions[strings.TrimSpace(strings.TrimPrefix(name, "sso-session "))] = s.values
+			continue
+		case strings.HasPrefix(name, "profile "):
+			name = strings.TrimSpace(strings.TrimPrefix(name, "profile "))
+		case name != "default":
+			continue
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		profiles = append(profiles, newProfile(name, s.values))
+	}
+
+	for i, p := range profiles {
+		if session, ok := sessions[p.SSOSession]; ok && p.SSOSession != "" {
+			profiles[i].SSOStartURL = session["sso_start_url"]
+			profiles[i].SSORegion = session["sso_region"]
+		}
+	}
+
+	for _, s := range credentials {
+		if seen[s.name] {
+			continue
+		}
+		seen[s.name] = true
+		profiles = append(profiles, newProfile(s.name, s.values))
+	}
+
+	return profiles, nil
+}
+
+func newProfile(name string, values map[string]string) Profile {
+	return Profile{
+		Name:          name,
+		Region:        values["region"],
+		SSOSession:    values["sso_session"],
+		SSOStartURL:   values["sso_start_url"],
+		SSORegion:     values["sso_region"],
+		SSOAccountID:  values["sso_account_id"],
+		SSORoleName:   values["sso_role_name"],
+		RoleARN:       values["role_arn"],
+		SourceProfile: values["source_profile"],
+	}
+}
+
+// FindProfile returns the profile with the given name from the shared config files.
+func FindProfile(name string) (Profile, error) {
+	profiles, err := LoadProfiles()
+	if err != nil {
+		return Profile{}, err
+	}
+	for _, p := range profiles {
+		if p.Name == name {
+			return p, nil
+		}
+	}
+	return Profile{}, fmt.Errorf("%w: %s", ErrProfileNotFound, name)
+}
@@ -0,0 +1,85 @@
+//go:build test
+// +build test
+
+package aws
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ConfigSuite struct {
+	suite.Suite
+}
+
+func TestConfigSuite(t *testing.T) {
+	suite.Run(t, new(ConfigSuite))
+}
+
+func (suite ConfigSuite) SetupTest() {
+	os.Setenv("AWS_CONFIG_FILE", filepath.Join("testdata", "config"))
+	os.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join("testdata", "credentials"))
+}
+
+func (suite ConfigSuite) TearDownTest() {
+	os.Unsetenv("AWS_CONFIG_FILE")
+	os.Unsetenv("AWS_SHARED_CREDENTIALS_FILE")
+}
+
+func (suite ConfigSuite) TestFiles() {
+	suite.Equal(filepath.Join("testdata", "config"), ConfigFile())
+	suite.Equal(filepath.Join("testdata", "credentials"), CredentialsFile())
+
+	home, err := os.UserHomeDir()
+	suite.Require().NoError(err)
+	os.Setenv("AWS_CONFIG_FILE", "~/aws/config")
+	suite.Equal(filepath.Join(home, "aws", "config"), ConfigFile())
+	os.Unsetenv("AWS_SHARED_CREDENTIALS_FILE")
+	suite.Equal(filepath.Join(home, ".aws", "credentials"), CredentialsFile())
+}
+
+func (suite ConfigSuite) TestParseINI() {
+	sections, err := parseINI(strings.NewReader("ignored = 1\n[a]\nx = 1\n  nested = 2\ny=3=4\nbad\n; comment\n[ b ]\n"))
+	suite.NoError(err)
+	suite.Equal([]section{
+		{name: "a", values: map[string]string{"x": "1", "y": "3=4"}},
+		{name: "b", values: map[string]string{}},
+	}, sections)
+}
+
+func (suite ConfigSuite) TestLoadProfiles() {
+	profiles, err := LoadProfiles()
+	suite.Require().NoError(err)
+	suite.Equal([]Profile{
+		{Name: "default", Region: "us-east-1"},
+		{Name: "dev", Region: "us-west-2", RoleARN: "arn:aws:iam::111111111111:role/dev", SourceProfile: "default"},
+		{Name: "prod", Region: "eu-west-1", SSOSession: "corp", SSOStartURL: "https://corp.awsapps.com/start", SSORegion: "us-east-2", SSOAccountID: "222222222222", SSORoleName: "AdministratorAccess"},
+		{Name: "legacy-sso", SSOStartURL: "https://legacy.awsapps.com/start", SSORegion: "us-east-1", SSOAccountID: "333333333333", SSORoleName: "ReadOnly"},
+		{Name: "ci"},
+	}, profiles)
+}
+
+func (suite ConfigSuite) TestLoadProfilesMissingFiles() {
+	os.Setenv("AWS_CONFIG_FILE", filepath.Join("testdata", "missing"))
+	profiles, err := LoadProfiles()
+	suite.NoError(err)
+	suite.Len(profiles, 2)
+
+	os.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join("testdata", "missing"))
+	_, err = LoadProfiles()
+	suite.True(errors.Is(err, ErrNoConfig))
+}
+
+func (suite ConfigSuite) TestFindProfile() {
+	p, err := FindProfile("prod")
+	suite.NoError(err)
+	suite.Equal("222222222222", p.SSOAccountID)
+
+	_, err = FindProfile("missing")
+	suite.True(errors.Is(err, ErrProfileNotFound))
+}
@@ -0,0 +1,55 @@
+package aws
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/eiladin/ekalias/eks"
+)
+
+var ErrNoCredentials = errors.New("no static credentials found")
+
+// ResolveCredentials returns the credentials from the AWS_ACCESS_KEY_ID and
+// AWS_SECRET_ACCESS_KEY environment variables, or the static keys of profile
+// in the shared credentials and config files.
+func ResolveCredentials(profile string) (eks.Credentials, error) {
+	if id, secret := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY"); id != "" && secret != "" {
+		return eks.Credentials{AccessKeyID: id, SecretAccessKey: secret, SessionToken: os.Getenv("AWS_SESSION_TOKEN")}, nil
+	}
+
+	if profile == "" {
+		profile = "default"
+	}
+
+	type location struct {
+		path    string
+		section string
+	}
+	locations := []location{
+		{path: CredentialsFile(), section: profile},
+		{path: ConfigFile(), section: "profile " + profile},
+	}
+	if profile == "default" {
+		locations = append(locations, location{path: ConfigFile(), section: "default"})
+	}
+
+	for _, l := range locations {
+		sections, err := readINI(l.path)
+		if err != nil {
+			continue
+		}
+		for _, s := range sections {
+			if s.name != l.section || s.values["aws_access_key_id"] == "" {
+				continue
+			}
+			return eks.Credentials{
+				AccessKeyID:     s.values["aws_access_key_id"],
+				SecretAccessKey: s.values["aws_secret_access_key"],
+				SessionToken:    s.values["aws_session_token"],
+			}, nil
+		}
+	}
+
+	return eks.Credentials{}, fmt.Errorf("%w for profile %s, export AWS_ACCESS_KEY_ID/AWS_SECRET_ACCESS_KEY or use the cli backend", ErrNoCredentials, profile)
+}
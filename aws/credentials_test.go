@@ -0,0 +1,56 @@
+//go:build test
+// +build test
+
+package aws
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/eiladin/ekalias/eks"
+	"github.com/stretchr/testify/suite"
+)
+
+type CredentialsSuite struct {
+	suite.Suite
+}
+
+func TestCredentialsSuite(t *testing.T) {
+	suite.Run(t, new(CredentialsSuite))
+}
+
+func (suite CredentialsSuite) SetupTest() {
+	os.Setenv("AWS_CONFIG_FILE", filepath.Join("testdata", "config"))
+	os.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join("testdata", "credentials"))
+}
+
+func (suite CredentialsSuite) TearDownTest() {
+	for _, env := range []string{"AWS_CONFIG_FILE", "AWS_SHARED_CREDENTIALS_FILE", "AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN"} {
+		os.Unsetenv(env)
+	}
+}
+
+func (suite CredentialsSuite) TestFromFiles() {
+	creds, err := ResolveCredentials("")
+	suite.NoError(err)
+	suite.Equal(eks.Credentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "secret"}, creds)
+
+	creds, err = ResolveCredentials("ci")
+	suite.NoError(err)
+	suite.Equal("AKIDCI", creds.AccessKeyID)
+
+	_, err = ResolveCredentials("prod")
+	suite.True(errors.Is(err, ErrNoCredentials))
+}
+
+func (suite CredentialsSuite) TestFromEnvironment() {
+	os.Setenv("AWS_ACCESS_KEY_ID", "AKIDENV")
+	os.Setenv("AWS_SECRET_ACCESS_KEY", "envsecret")
+	os.Setenv("AWS_SESSION_TOKEN", "envtoken")
+
+	creds, err := ResolveCredentials("prod")
+	suite.NoError(err)
+	suite.Equal(eks.Credentials{AccessKeyID: "AKIDENV", SecretAccessKey: "envsecret", SessionToken: "envtoken"}, creds)
+}
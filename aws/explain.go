@@ -0,0 +1,71 @@
+package aws
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/eiladin/ekalias/console"
+	"github.com/eiladin/ekalias/eks"
+)
+
+var ErrCredentialsExpired = errors.New("aws credentials expired")
+var ErrAccessDenied = errors.New("access denied")
+
+// Error codes in aws cli output and EKS API responses that mean the
+// credentials of a profile have expired or lack a permission.
+var (
+	expiredCodes = []string{"ExpiredToken", "RequestExpired", "Token has expired", "SSO session associated with this profile has expired", "Error loading SSO Token"}
+	deniedCodes  = []string{"AccessDenied", "UnauthorizedOperation", "UnauthorizedException"}
+)
+
+// explainedError tells the user what an error from AWS means for a profile,
+// while still matching both its kind and the original error.
+type explainedError struct {
+	kind error
+	msg  string
+	err  error
+}
+
+func (e explainedError) Error() string        { return e.msg }
+func (e explainedError) Unwrap() error        { return e.err }
+func (e explainedError) Is(target error) bool { return target == e.kind }
+
+// explain recognises expired credentials and missing permissions in an
+// error the aws cli or the EKS API returned for action, and returns any other
+// error as it is.
+func explain(profile, action string, err error) error {
+	var detail string
+	var cmdErr *console.CommandError
+	var apiErr eks.APIError
+	switch {
+	case errors.As(err, &cmdErr):
+		detail = cmdErr.Stderr
+	case errors.As(err, &apiErr):
+		detail = apiErr.Type
+	default:
+		return err
+	}
+	if profile == "" {
+		profile = "default"
+	}
+
+	switch {
+	case containsAny(detail, expiredCodes):
+		msg := fmt.Sprintf("%s for profile %s, run `aws sso login --profile %s` or refresh them, then try again (%s)", ErrCredentialsExpired, profile, profile, err)
+		return explainedError{kind: ErrCredentialsExpired, msg: msg, err: err}
+	case containsAny(detail, deniedCodes):
+		msg := fmt.Sprintf("%s: profile %s is not allowed to run %s, check its role and policies (%s)", ErrAccessDenied, profile, action, err)
+		return explainedError{kind: ErrAccessDenied, msg: msg, err: err}
+	}
+	return err
+}
+
+func containsAny(s string, subs []string) bool {
+	for _, sub := range subs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
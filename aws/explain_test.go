@@ -0,0 +1,77 @@
+//go:build test
+// +build test
+
+package aws
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/eiladin/ekalias/console"
+	"github.com/eiladin/ekalias/eks"
+	"github.com/eiladin/ekalias/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type ExplainSuite struct {
+	suite.Suite
+}
+
+func TestExplainSuite(t *testing.T) {
+	suite.Run(t, new(ExplainSuite))
+}
+
+func (suite ExplainSuite) TestExplain() {
+	expired := &console.CommandError{Name: "/usr/bin/aws", Args: []string{"eks", "list-clusters"}, ExitCode: 255, Stderr: "\nAn error occurred (ExpiredTokenException) when calling the ListClusters operation: The security token included in the request is expired\n"}
+	denied := &console.CommandError{Name: "/usr/bin/aws", Args: []string{"eks", "list-clusters"}, ExitCode: 254, Stderr: "\nAn error occurred (AccessDeniedException) when calling the ListClusters operation: User is not authorized\n"}
+	other := &console.CommandError{Name: "/usr/bin/aws", Args: []string{"eks", "list-clusters"}, ExitCode: 252, Stderr: "aws: error: argument --region: expected one argument\n"}
+	sso := &console.CommandError{Name: "/usr/bin/aws", Args: []string{"eks", "list-clusters"}, ExitCode: 255, Stderr: "Error when retrieving token from sso: Token has expired and refresh failed\n"}
+
+	cases := []struct {
+		profile  string
+		err      error
+		kind     error
+		expected string
+	}{
+		{profile: "dev", err: expired, kind: ErrCredentialsExpired, expected: "aws credentials expired for profile dev, run `aws sso login --profile dev` or refresh them, then try again (" + expired.Error() + ")"},
+		{profile: "", err: sso, kind: ErrCredentialsExpired, expected: "aws credentials expired for profile default, run `aws sso login --profile default` or refresh them, then try again (" + sso.Error() + ")"},
+		{profile: "dev", err: denied, kind: ErrAccessDenied, expected: "access denied: profile dev is not allowed to run aws eks list-clusters, check its role and policies (" + denied.Error() + ")"},
+		{profile: "dev", err: eks.APIError{StatusCode: 403, Type: "AccessDeniedException", Message: "no"}, kind: ErrAccessDenied, expected: "access denied: profile dev is not allowed to run aws eks list-clusters, check its role and policies (eks: AccessDeniedException: no)"},
+		{profile: "dev", err: eks.APIError{StatusCode: 403, Type: "ExpiredTokenException", Message: "expired"}, kind: ErrCredentialsExpired},
+		{profile: "dev", err: other},
+		{profile: "dev", err: errors.New("boom")},
+		{profile: "dev"},
+	}
+
+	for _, c := range cases {
+		err := explain(c.profile, "aws eks list-clusters", c.err)
+		if c.kind == nil {
+			suite.Equal(c.err, err)
+			continue
+		}
+		suite.True(errors.Is(err, c.kind), err.Error())
+		suite.True(errors.Is(err, c.err))
+		if c.expected != "" {
+			suite.Equal(c.expected, err.Error())
+		}
+	}
+}
+
+func (suite ExplainSuite) TestListClustersExplained() {
+	e := new(mocks.Executor)
+	pe := new(mocks.Executor)
+	e.On("FindExecutable", executable).Return(executable, nil)
+	e.On("WithEnv", "AWS_PROFILE=dev").Return(pe)
+	cmdErr := &console.CommandError{Name: executable, Args: []string{"eks", "list-clusters"}, ExitCode: 255, Stderr: "An error occurred (ExpiredToken) when calling the ListClusters operation"}
+	pe.On("ExecCommand", mock.Anything, executable, "eks", "list-clusters", "--region", "us-east-1", "--profile", "dev").Return("", cmdErr)
+
+	b, err := New(e).backendFor("dev")
+	suite.Require().NoError(err)
+	_, err = b.ListClusters("us-east-1")
+	suite.True(errors.Is(err, ErrCredentialsExpired))
+
+	var target *console.CommandError
+	suite.Require().True(errors.As(err, &target))
+	suite.Equal(255, target.ExitCode)
+}
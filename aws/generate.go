@@ -0,0 +1,177 @@
+package aws
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/eiladin/ekalias/shell"
+)
+
+// DefaultAliasTemplate names generated aliases after the profile, region and cluster.
+const DefaultAliasTemplate = "{{.Profile}}-{{.Region}}-{{.Cluster}}"
+
+var ErrDuplicateAlias = errors.New("alias name template produced the same name twice")
+
+// GenerateOptions configures GenerateAll. Without Profiles every profile is
+// used, and without Regions every region enabled for each profile is scanned.
+// Validate, when set, is called with every alias name before a kube context
+// is created for it, and the cluster is reported with its error instead.
+type GenerateOptions struct {
+	Profiles []string
+	Regions  []string
+	Template string
+	Workers  int
+	Validate func(name string) error
+}
+
+// AliasName holds the values available to the alias name template.
+type AliasName struct {
+	Profile string
+	Region  string
+	Cluster string
+}
+
+// Generated is the outcome for one cluster. Profiles and regions that could
+// not be listed are reported with an empty Cluster.
+type Generated struct {
+	Profile string
+	Region  string
+	Cluster string
+	Alias   string
+	Context string
+	Err     error
+}
+
+type target struct {
+	profile string
+	region  string
+	backend ClusterBackend
+}
+
+// GenerateAll discovers every cluster of every profile and region and
+// creates a kube context for each, named with the alias name template.
+// Discovery runs concurrently; kubeconfig updates run one at a time as
+// they all write to the same file. Ctrl-C and --timeout stop it with their
+// error, returned together with the kube contexts created so far.
+func (aws AWS) GenerateAll(opts GenerateOptions) ([]Generated, error) {
+	text := opts.Template
+	if text == "" {
+		text = DefaultAliasTemplate
+	}
+	name, err := shell.NewNamer("alias", text)
+	if err != nil {
+		return nil, err
+	}
+
+	names := opts.Profiles
+	if len(names) == 0 {
+		if names, err = aws.findProfiles(); err != nil {
+			return nil, err
+		}
+	}
+	var profiles []string
+	for _, p := range names {
+		if p != "" {
+			profiles = append(profiles, p)
+		}
+	}
+
+	var results []Generated
+	var targets []target
+	regions := make([][]string, len(profiles))
+	backends := make([]ClusterBackend, len(profiles))
+	errs := make([]error, len(profiles))
+	var stop interruption
+	parallel(len(profiles), opts.Workers, func(i int) {
+		if stop.err() != nil {
+			return
+		}
+		backends[i], errs[i] = aws.profileBackend(profiles[i])
+		if errs[i] == nil {
+			regions[i] = opts.Regions
+			if len(regions[i]) == 0 {
+				regions[i], errs[i] = backends[i].Regions()
+			}
+		}
+		stop.record(errs[i])
+	})
+	if err := stop.err(); err != nil {
+		return nil, err
+	}
+	for i, p := range profiles {
+		if errs[i] != nil {
+			results = append(results, Generated{Profile: p, Err: errs[i]})
+			continue
+		}
+		for _, r := range regions[i] {
+			targets = append(targets, target{profile: p, region: r, backend: backends[i]})
+		}
+	}
+
+	clusters := make([][]string, len(targets))
+	errs = make([]error, len(targets))
+	parallel(len(targets), opts.Workers, func(i int) {
+		if stop.err() != nil {
+			return
+		}
+		clusters[i], errs[i] = targets[i].backend.ListClusters(targets[i].region)
+		stop.record(errs[i])
+	})
+	if err := stop.err(); err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	var stopped error
+update:
+	for i, t := range targets {
+		if errs[i] != nil {
+			results = append(results, Generated{Profile: t.profile, Region: t.region, Err: errs[i]})
+			continue
+		}
+		for _, c := range clusters[i] {
+			g := Generated{Profile: t.profile, Region: t.region, Cluster: c}
+			g.Alias, g.Err = name(AliasName{Profile: t.profile, Region: t.region, Cluster: c})
+			if g.Err == nil && seen[g.Alias] {
+				g.Err = fmt.Errorf("%w: %s", ErrDuplicateAlias, g.Alias)
+			}
+			if g.Err == nil && opts.Validate != nil {
+				g.Err = opts.Validate(g.Alias)
+			}
+			if g.Err == nil {
+				seen[g.Alias] = true
+				g.Context, g.Err = t.backend.UpdateKubeconfig(t.region, c, g.Alias)
+			}
+			if interrupted(g.Err) {
+				stopped = g.Err
+				break update
+			}
+			results = append(results, g)
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.Profile != b.Profile {
+			return a.Profile < b.Profile
+		}
+		if a.Region != b.Region {
+			return a.Region < b.Region
+		}
+		return a.Cluster < b.Cluster
+	})
+	return results, stopped
+}
+
+// profileBackend returns the backend for profile after making sure its sso
+// session, if it uses one, has not expired.
+func (aws AWS) profileBackend(profile string) (ClusterBackend, error) {
+	opts := aws.opts
+	opts.Profile = profile
+	opts.NonInteractive = true
+	if err := aws.WithOptions(opts).ensureSSOSession(); err != nil {
+		return nil, err
+	}
+	return aws.backendFor(profile)
+}
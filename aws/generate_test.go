@@ -0,0 +1,156 @@
+//go:build test
+// +build test
+
+package aws
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/eiladin/ekalias/console"
+	"github.com/eiladin/ekalias/mocks"
+	"github.com/eiladin/ekalias/sso"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type GenerateSuite struct {
+	suite.Suite
+	cacheDir string
+}
+
+func TestGenerateSuite(t *testing.T) {
+	suite.Run(t, new(GenerateSuite))
+}
+
+func (suite *GenerateSuite) SetupTest() {
+	dir, err := ioutil.TempDir("", "ekalias-sso-cache")
+	suite.NoError(err)
+	suite.cacheDir = dir
+	ssoCacheDir = func() string { return dir }
+	os.Setenv("AWS_CONFIG_FILE", filepath.Join("testdata", "config"))
+	os.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join("testdata", "credentials"))
+}
+
+func (suite *GenerateSuite) TearDownTest() {
+	os.RemoveAll(suite.cacheDir)
+	ssoCacheDir = sso.CacheDir
+	os.Unsetenv("AWS_CONFIG_FILE")
+	os.Unsetenv("AWS_SHARED_CREDENTIALS_FILE")
+}
+
+// withProfile expects e to hand out an executor for commands run under
+// profile, and returns it.
+func withProfile(e *mocks.Executor, profile string) *mocks.Executor {
+	pe := new(mocks.Executor)
+	e.On("WithEnv", "AWS_PROFILE="+profile).Return(pe)
+	return pe
+}
+
+func (suite *GenerateSuite) TestGenerateAll() {
+	e := new(mocks.Executor)
+	e.On("FindExecutable", executable).Return(executable, nil)
+	def := withProfile(e, "default")
+	def.On("ExecCommand", mock.Anything, executable, "ec2", "describe-regions", "--output", "json", "--region", "us-east-1", "--profile", "default").Return(`{"Regions":[{"RegionName":"us-east-1"},{"RegionName":"eu-west-1"}]}`, nil)
+	def.On("ExecCommand", mock.Anything, executable, "eks", "list-clusters", "--region", "us-east-1", "--profile", "default").Return(`{"clusters":["main"]}`, nil)
+	def.On("ExecCommand", mock.Anything, executable, "eks", "list-clusters", "--region", "eu-west-1", "--profile", "default").Return("", errors.New("access denied"))
+	def.On("ExecCommand", mock.Anything, executable, "eks", "update-kubeconfig", "--region", "us-east-1", "--name", "main", "--alias", "default-us-east-1-main", "--profile", "default").Return("Updated context default-us-east-1-main", nil)
+	dev := withProfile(e, "dev")
+	dev.On("ExecCommand", mock.Anything, executable, "ec2", "describe-regions", "--output", "json", "--region", "us-west-2", "--profile", "dev").Return(`{"Regions":[{"RegionName":"us-west-2"}]}`, nil)
+	dev.On("ExecCommand", mock.Anything, executable, "eks", "list-clusters", "--region", "us-west-2", "--profile", "dev").Return(`{"clusters":["main","Team A"]}`, nil)
+	dev.On("ExecCommand", mock.Anything, executable, "eks", "update-kubeconfig", "--region", "us-west-2", "--name", "main", "--alias", "dev-us-west-2-main", "--profile", "dev").Return("Updated context dev-us-west-2-main", nil)
+	dev.On("ExecCommand", mock.Anything, executable, "eks", "update-kubeconfig", "--region", "us-west-2", "--name", "Team A", "--alias", "dev-us-west-2-Team-A", "--profile", "dev").Return("", errors.New("update failed"))
+
+	results, err := New(e).GenerateAll(GenerateOptions{Profiles: []string{"dev", "default", "prod", ""}})
+	suite.NoError(err)
+	suite.Len(results, 5)
+
+	suite.Equal(Generated{Profile: "default", Region: "eu-west-1", Err: errors.New("access denied")}, results[0])
+	suite.Equal(Generated{Profile: "default", Region: "us-east-1", Cluster: "main", Alias: "default-us-east-1-main", Context: "default-us-east-1-main"}, results[1])
+	suite.Equal(Generated{Profile: "dev", Region: "us-west-2", Cluster: "Team A", Alias: "dev-us-west-2-Team-A", Err: errors.New("update failed")}, results[2])
+	suite.Equal(Generated{Profile: "dev", Region: "us-west-2", Cluster: "main", Alias: "dev-us-west-2-main", Context: "dev-us-west-2-main"}, results[3])
+	suite.Equal("prod", results[4].Profile)
+	suite.True(errors.Is(results[4].Err, ErrSSOSessionExpired))
+}
+
+func (suite *GenerateSuite) TestGenerateAllTemplate() {
+	e := new(mocks.Executor)
+	e.On("FindExecutable", executable).Return(executable, nil)
+	dev := withProfile(e, "dev")
+	dev.On("ExecCommand", mock.Anything, executable, "eks", "list-clusters", "--region", "us-east-1", "--profile", "dev").Return(`{"clusters":["main"]}`, nil)
+	dev.On("ExecCommand", mock.Anything, executable, "eks", "list-clusters", "--region", "us-west-2", "--profile", "dev").Return(`{"clusters":["main"]}`, nil)
+	dev.On("ExecCommand", mock.Anything, executable, "eks", "update-kubeconfig", "--region", "us-east-1", "--name", "main", "--alias", "main", "--profile", "dev").Return("", nil)
+
+	results, err := New(e).GenerateAll(GenerateOptions{
+		Profiles: []string{"dev"},
+		Regions:  []string{"us-east-1", "us-west-2"},
+		Template: "{{.Cluster | lower}}",
+	})
+	suite.NoError(err)
+	suite.Len(results, 2)
+	suite.NoError(results[0].Err)
+	suite.Equal("main", results[0].Context)
+	suite.True(errors.Is(results[1].Err, ErrDuplicateAlias))
+	dev.AssertNotCalled(suite.T(), "ExecCommand", mock.Anything, executable, "ec2", "describe-regions", "--output", "json", "--region", "us-west-2", "--profile", "dev")
+	e.AssertNotCalled(suite.T(), "ExecCommand", mock.Anything, mock.Anything, mock.Anything)
+
+	_, err = New(e).GenerateAll(GenerateOptions{Template: "{{.Cluster"})
+	suite.Error(err)
+	results, err = New(e).GenerateAll(GenerateOptions{Profiles: []string{"dev"}, Regions: []string{"us-east-1"}, Template: "{{.Account}}"})
+	suite.NoError(err)
+	suite.Error(results[0].Err)
+}
+
+func (suite *GenerateSuite) TestGenerateAllValidate() {
+	e := new(mocks.Executor)
+	e.On("FindExecutable", executable).Return(executable, nil)
+	dev := withProfile(e, "dev")
+	dev.On("ExecCommand", mock.Anything, executable, "eks", "list-clusters", "--region", "us-east-1", "--profile", "dev").Return(`{"clusters":["main","kubectl"]}`, nil)
+	dev.On("ExecCommand", mock.Anything, executable, "eks", "update-kubeconfig", "--region", "us-east-1", "--name", "main", "--alias", "main", "--profile", "dev").Return("", nil)
+
+	invalid := errors.New("shadows kubectl")
+	results, err := New(e).GenerateAll(GenerateOptions{
+		Profiles: []string{"dev"},
+		Regions:  []string{"us-east-1"},
+		Template: "{{.Cluster}}",
+		Validate: func(name string) error {
+			if name == "kubectl" {
+				return invalid
+			}
+			return nil
+		},
+	})
+	suite.NoError(err)
+	suite.Len(results, 2)
+	suite.Equal(invalid, results[0].Err)
+	suite.NoError(results[1].Err)
+	dev.AssertNotCalled(suite.T(), "ExecCommand", mock.Anything, executable, "eks", "update-kubeconfig", "--region", "us-east-1", "--name", "kubectl", "--alias", "kubectl", "--profile", "dev")
+}
+
+func (suite *GenerateSuite) TestGenerateAllInterrupted() {
+	e := new(mocks.Executor)
+	e.On("FindExecutable", executable).Return(executable, nil)
+	dev := withProfile(e, "dev")
+	dev.On("ExecCommand", mock.Anything, executable, "eks", "list-clusters", "--region", "us-east-1", "--profile", "dev").Return("", console.ErrCancelled)
+	dev.On("ExecCommand", mock.Anything, executable, "eks", "list-clusters", "--region", "us-west-2", "--profile", "dev").Return(`{"clusters":["main"]}`, nil)
+
+	results, err := New(e).GenerateAll(GenerateOptions{Profiles: []string{"dev"}, Regions: []string{"us-east-1", "us-west-2"}, Workers: 1})
+	suite.True(errors.Is(err, console.ErrCancelled))
+	suite.Empty(results)
+	dev.AssertNotCalled(suite.T(), "ExecCommand", mock.Anything, executable, "eks", "list-clusters", "--region", "us-west-2", "--profile", "dev")
+
+	e = new(mocks.Executor)
+	e.On("FindExecutable", executable).Return(executable, nil)
+	dev = withProfile(e, "dev")
+	dev.On("ExecCommand", mock.Anything, executable, "eks", "list-clusters", "--region", "us-east-1", "--profile", "dev").Return(`{"clusters":["a","b","c"]}`, nil)
+	dev.On("ExecCommand", mock.Anything, executable, "eks", "update-kubeconfig", "--region", "us-east-1", "--name", "a", "--alias", "a", "--profile", "dev").Return("", nil)
+	dev.On("ExecCommand", mock.Anything, executable, "eks", "update-kubeconfig", "--region", "us-east-1", "--name", "b", "--alias", "b", "--profile", "dev").Return("", console.ErrTimeout)
+
+	results, err = New(e).GenerateAll(GenerateOptions{Profiles: []string{"dev"}, Regions: []string{"us-east-1"}, Template: "{{.Cluster}}"})
+	suite.True(errors.Is(err, console.ErrTimeout))
+	suite.Equal([]Generated{{Profile: "dev", Region: "us-east-1", Cluster: "a", Alias: "a", Context: "a"}}, results)
+	dev.AssertNotCalled(suite.T(), "ExecCommand", mock.Anything, executable, "eks", "update-kubeconfig", "--region", "us-east-1", "--name", "c", "--alias", "c", "--profile", "dev")
+}
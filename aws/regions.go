@@ -0,0 +1,104 @@
+package aws
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/eiladin/ekalias/console"
+)
+
+var ErrInvalidRegion = errors.New("invalid region")
+
+var regionPattern = regexp.MustCompile(`^[a-z]{2}(-gov|-iso[a-z]?)?-[a-z]+-[0-9]+$`)
+
+// KnownRegions lists the regions offered by the region picker, across the
+// aws, aws-us-gov and aws-cn partitions.
+var KnownRegions = []string{
+	"us-east-1", "us-east-2", "us-west-1", "us-west-2",
+	"af-south-1",
+	"ap-east-1", "ap-south-1", "ap-south-2", "ap-southeast-1", "ap-southeast-2", "ap-southeast-3", "ap-southeast-4",
+	"ap-northeast-1", "ap-northeast-2", "ap-northeast-3",
+	"ca-central-1", "ca-west-1",
+	"eu-central-1", "eu-central-2", "eu-west-1", "eu-west-2", "eu-west-3", "eu-south-1", "eu-south-2", "eu-north-1",
+	"il-central-1",
+	"me-south-1", "me-central-1",
+	"sa-east-1",
+	"us-gov-east-1", "us-gov-west-1",
+	"cn-north-1", "cn-northwest-1",
+}
+
+// ValidateRegion rejects strings that cannot be a region name, such as us-east1.
+func ValidateRegion(region string) error {
+	if region == AllRegions || regionPattern.MatchString(region) {
+		return nil
+	}
+	return fmt.Errorf("%w: %q (expected a region like us-east-1)", ErrInvalidRegion, region)
+}
+
+// profileName returns the profile commands run under.
+func (aws AWS) profileName() string {
+	if aws.opts.Profile != "" {
+		return aws.opts.Profile
+	}
+	return os.Getenv("AWS_PROFILE")
+}
+
+// DefaultRegion returns the region configured for the selected profile.
+func (aws AWS) DefaultRegion() string {
+	for _, env := range []string{"AWS_REGION", "AWS_DEFAULT_REGION"} {
+		if r := os.Getenv(env); r != "" {
+			return r
+		}
+	}
+	name := aws.profileName()
+	if name == "" {
+		name = "default"
+	}
+	if p, err := FindProfile(name); err == nil {
+		return p.Region
+	}
+	return ""
+}
+
+// selectRegion returns --region, or lets the user pick one with the
+// profile's region offered first.
+func (aws AWS) selectRegion() (string, error) {
+	if aws.opts.Region != "" {
+		return aws.opts.Region, ValidateRegion(aws.opts.Region)
+	}
+
+	region := aws.DefaultRegion()
+	if aws.opts.NonInteractive {
+		if region == "" {
+			return "", fmt.Errorf("%w: use --region", console.ErrNonInteractive)
+		}
+		return region, ValidateRegion(region)
+	}
+
+	defaultLabel := fmt.Sprintf("%s (profile default)", region)
+	allLabel := fmt.Sprintf("%s (scan every enabled region)", AllRegions)
+	var labels []string
+	if region != "" {
+		labels = append(labels, defaultLabel)
+	}
+	labels = append(labels, allLabel)
+	for _, r := range KnownRegions {
+		if r != region {
+			labels = append(labels, r)
+		}
+	}
+
+	selected, err := aws.executor.SelectValueFromList(labels, "AWS Region", nil)
+	if err != nil {
+		return "", err
+	}
+	switch selected {
+	case defaultLabel:
+		selected = region
+	case allLabel:
+		selected = AllRegions
+	}
+	return selected, ValidateRegion(selected)
+}
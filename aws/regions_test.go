@@ -0,0 +1,107 @@
+//go:build test
+// +build test
+
+package aws
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/eiladin/ekalias/console"
+	"github.com/eiladin/ekalias/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type RegionsSuite struct {
+	suite.Suite
+}
+
+func TestRegionsSuite(t *testing.T) {
+	suite.Run(t, new(RegionsSuite))
+}
+
+func (suite RegionsSuite) SetupTest() {
+	os.Setenv("AWS_CONFIG_FILE", filepath.Join("testdata", "config"))
+	os.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join("testdata", "credentials"))
+}
+
+func (suite RegionsSuite) TearDownTest() {
+	for _, env := range []string{"AWS_CONFIG_FILE", "AWS_SHARED_CREDENTIALS_FILE", "AWS_PROFILE", "AWS_REGION", "AWS_DEFAULT_REGION"} {
+		os.Unsetenv(env)
+	}
+}
+
+func (suite RegionsSuite) TestValidateRegion() {
+	for _, r := range append(KnownRegions, AllRegions, "us-isob-east-1") {
+		suite.NoError(ValidateRegion(r), r)
+	}
+	for _, r := range []string{"", "us-east1", "US-EAST-1", "useast-1", "us-east-1a", "us-east-1; rm -rf /"} {
+		suite.True(errors.Is(ValidateRegion(r), ErrInvalidRegion), r)
+	}
+}
+
+func (suite RegionsSuite) TestDefaultRegion() {
+	suite.Equal("us-east-1", New(nil).DefaultRegion())
+	suite.Equal("eu-west-1", New(nil).WithOptions(Options{Profile: "prod"}).DefaultRegion())
+
+	suite.Equal("", New(nil).WithOptions(Options{Profile: "legacy-sso"}).DefaultRegion())
+	suite.Equal("", New(nil).WithOptions(Options{Profile: "missing"}).DefaultRegion())
+
+	os.Setenv("AWS_PROFILE", "dev")
+	suite.Equal("us-west-2", New(nil).DefaultRegion())
+
+	os.Setenv("AWS_DEFAULT_REGION", "ca-central-1")
+	suite.Equal("ca-central-1", New(nil).DefaultRegion())
+}
+
+func (suite RegionsSuite) TestSelectRegion() {
+	e := new(mocks.Executor)
+	e.On("SelectValueFromList", mock.Anything, "AWS Region", mock.Anything).Return("eu-west-1 (profile default)", nil).Once()
+	a := New(e).WithOptions(Options{Profile: "prod"})
+
+	res, err := a.selectRegion()
+	suite.NoError(err)
+	suite.Equal("eu-west-1", res)
+	labels := e.Calls[0].Arguments.Get(0).([]string)
+	suite.Equal([]string{"eu-west-1 (profile default)", "all (scan every enabled region)", "us-east-1"}, labels[:3])
+	suite.NotContains(labels, "eu-west-1")
+	suite.Contains(labels, "us-gov-west-1")
+	suite.Contains(labels, "cn-north-1")
+
+	e.On("SelectValueFromList", mock.Anything, "AWS Region", mock.Anything).Return("all (scan every enabled region)", nil).Once()
+	res, err = a.selectRegion()
+	suite.NoError(err)
+	suite.Equal(AllRegions, res)
+
+	e.On("SelectValueFromList", mock.Anything, "AWS Region", mock.Anything).Return("", errors.New("select")).Once()
+	_, err = a.selectRegion()
+	suite.Error(err)
+}
+
+func (suite RegionsSuite) TestSelectRegionWithOptions() {
+	cases := []struct {
+		opts          Options
+		expected      string
+		expectedError error
+	}{
+		{opts: Options{Region: "us-west-1"}, expected: "us-west-1"},
+		{opts: Options{Region: "us-east1"}, expected: "us-east1", expectedError: ErrInvalidRegion},
+		{opts: Options{Profile: "prod", NonInteractive: true}, expected: "eu-west-1"},
+		{opts: Options{Profile: "legacy-sso", NonInteractive: true}, expectedError: console.ErrNonInteractive},
+	}
+
+	for _, c := range cases {
+		e := new(mocks.Executor)
+		res, err := New(e).WithOptions(c.opts).selectRegion()
+		if c.expectedError != nil {
+			suite.True(errors.Is(err, c.expectedError), err)
+		} else {
+			suite.NoError(err)
+		}
+		suite.Equal(c.expected, res)
+		e.AssertNotCalled(suite.T(), "SelectValueFromList", mock.Anything, mock.Anything, mock.Anything)
+	}
+}
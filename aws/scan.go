@@ -0,0 +1,125 @@
+package aws
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/eiladin/ekalias/console"
+)
+
+const (
+	AllRegions     = "all"
+	defaultWorkers = 8
+)
+
+type clusterRef struct {
+	region string
+	name   string
+}
+
+func (c clusterRef) String() string {
+	return c.region + "/" + c.name
+}
+
+// parallel calls fn for every index in [0, n) using at most workers goroutines.
+func parallel(n, workers int, fn func(i int)) {
+	if workers < 1 {
+		workers = defaultWorkers
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers && w < n; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				fn(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// interrupted reports whether err comes from Ctrl-C or --timeout, which end
+// a whole scan instead of the one region or profile that saw them.
+func interrupted(err error) bool {
+	return errors.Is(err, console.ErrCancelled) || errors.Is(err, console.ErrTimeout)
+}
+
+// interruption keeps the first interrupted error of concurrent jobs, so the
+// jobs that have not started yet can be skipped.
+type interruption struct {
+	mu    sync.Mutex
+	first error
+}
+
+func (i *interruption) record(err error) {
+	if !interrupted(err) {
+		return
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if i.first == nil {
+		i.first = err
+	}
+}
+
+func (i *interruption) err() error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.first
+}
+
+// scanRegions lists the clusters of every region concurrently. Regions that
+// fail are reported through the executor and skipped, unless the
+// credentials have expired, which fails every region, or no region could be
+// listed at all; then the error is returned. Ctrl-C and --timeout stop the
+// scan.
+func (aws AWS) scanRegions(backend ClusterBackend, regions []string) ([]clusterRef, error) {
+	type result struct {
+		clusters []string
+		err      error
+	}
+	results := make([]result, len(regions))
+	var stop interruption
+	parallel(len(regions), aws.opts.Workers, func(i int) {
+		if stop.err() != nil {
+			return
+		}
+		clusters, err := backend.ListClusters(regions[i])
+		stop.record(err)
+		results[i] = result{clusters: clusters, err: err}
+	})
+	if err := stop.err(); err != nil {
+		return nil, err
+	}
+
+	var refs []clusterRef
+	var failed []int
+	for i, r := range results {
+		switch {
+		case errors.Is(r.err, ErrCredentialsExpired):
+			return nil, r.err
+		case r.err != nil:
+			failed = append(failed, i)
+		}
+		for _, c := range r.clusters {
+			refs = append(refs, clusterRef{region: regions[i], name: c})
+		}
+	}
+	if len(failed) > 0 && len(failed) == len(regions) {
+		return nil, results[failed[0]].err
+	}
+	for _, i := range failed {
+		aws.executor.Warn(fmt.Sprintf("skipping %s: %s", regions[i], results[i].err.Error()))
+	}
+	sort.SliceStable(refs, func(i, j int) bool { return refs[i].String() < refs[j].String() })
+	return refs, nil
+}
@@ -0,0 +1,149 @@
+//go:build test
+// +build test
+
+package aws
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/eiladin/ekalias/console"
+	"github.com/eiladin/ekalias/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type ScanSuite struct {
+	suite.Suite
+}
+
+func TestScanSuite(t *testing.T) {
+	suite.Run(t, new(ScanSuite))
+}
+
+func (suite ScanSuite) SetupTest() {
+	os.Setenv("AWS_CONFIG_FILE", filepath.Join("testdata", "missing"))
+	os.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join("testdata", "missing"))
+}
+
+func (suite ScanSuite) TearDownTest() {
+	os.Unsetenv("AWS_CONFIG_FILE")
+	os.Unsetenv("AWS_SHARED_CREDENTIALS_FILE")
+}
+
+func (suite ScanSuite) TestParallel() {
+	var running, max int32
+	var mu sync.Mutex
+	seen := map[int]bool{}
+
+	parallel(20, 3, func(i int) {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			m := atomic.LoadInt32(&max)
+			if n <= m || atomic.CompareAndSwapInt32(&max, m, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		mu.Lock()
+		seen[i] = true
+		mu.Unlock()
+		atomic.AddInt32(&running, -1)
+	})
+
+	suite.Len(seen, 20)
+	suite.LessOrEqual(int(max), 3)
+}
+
+func (suite ScanSuite) TestCreateKubeContextAllRegions() {
+	e := new(mocks.Executor)
+	e.On("FindExecutable", executable).Return(executable, nil)
+	e.On("SelectValueFromList", mock.Anything, "AWS Region", mock.Anything).Return("all (scan every enabled region)", nil)
+	e.On("PromptInput", "Kube Context Alias: ").Return("", nil)
+	e.On("ExecCommand", mock.Anything, executable, "ec2", "describe-regions", "--output", "json").Return(`{"Regions":[{"RegionName":"us-west-2"},{"RegionName":"ap-east-1"},{"RegionName":"us-east-1"}]}`, nil)
+	e.On("ExecCommand", mock.Anything, executable, "eks", "list-clusters", "--region", "us-east-1").Return(`{"clusters":["prod"]}`, nil)
+	e.On("ExecCommand", mock.Anything, executable, "eks", "list-clusters", "--region", "us-west-2").Return(`{"clusters":["dev","prod"]}`, nil)
+	e.On("ExecCommand", mock.Anything, executable, "eks", "list-clusters", "--region", "ap-east-1").Return("", errors.New("UnrecognizedClientException"))
+	e.On("Warn", "skipping ap-east-1: UnrecognizedClientException").Return()
+	e.On("SelectValueFromList", []string{"us-east-1/prod", "us-west-2/dev", "us-west-2/prod"}, "Cluster", mock.Anything).Return("us-west-2/prod", nil)
+	e.On("ExecCommand", mock.Anything, executable, "eks", "update-kubeconfig", "--region", "us-west-2", "--name", "prod").Return("Updated context arn:aws:eks:us-west-2:accountID:cluster/prod in /home/user/.kube/config", nil)
+
+	res, err := New(e).CreateKubeContext()
+	suite.NoError(err)
+	suite.Equal("arn:aws:eks:us-west-2:accountID:cluster/prod", res)
+	e.AssertCalled(suite.T(), "Warn", "skipping ap-east-1: UnrecognizedClientException")
+}
+
+func (suite ScanSuite) TestCreateKubeContextAllRegionsError() {
+	e := new(mocks.Executor)
+	e.On("FindExecutable", executable).Return(executable, nil)
+	e.On("ExecCommand", mock.Anything, executable, "ec2", "describe-regions", "--output", "json").Return("", errors.New("denied"))
+
+	_, err := New(e).WithOptions(Options{Region: AllRegions}).CreateKubeContext()
+	suite.Error(err)
+}
+
+func (suite ScanSuite) TestCreateKubeContextAllRegionsExplained() {
+	expired := &console.CommandError{Name: executable, Args: []string{"eks", "list-clusters"}, ExitCode: 255, Stderr: "An error occurred (ExpiredTokenException) when calling the ListClusters operation"}
+	denied := &console.CommandError{Name: executable, Args: []string{"eks", "list-clusters"}, ExitCode: 255, Stderr: "An error occurred (AccessDeniedException) when calling the ListClusters operation"}
+	cases := []struct {
+		east, west    error
+		expectedError error
+	}{
+		{east: expired, expectedError: ErrCredentialsExpired},
+		{east: denied, west: denied, expectedError: ErrAccessDenied},
+		{east: denied, expectedError: ErrNoClusters},
+	}
+
+	for _, c := range cases {
+		e := new(mocks.Executor)
+		e.On("FindExecutable", executable).Return(executable, nil)
+		e.On("Warn", mock.Anything).Return()
+		e.On("ExecCommand", mock.Anything, executable, "ec2", "describe-regions", "--output", "json").Return(`{"Regions":[{"RegionName":"us-east-1"},{"RegionName":"us-west-2"}]}`, nil)
+		e.On("ExecCommand", mock.Anything, executable, "eks", "list-clusters", "--region", "us-east-1").Return("", c.east)
+		e.On("ExecCommand", mock.Anything, executable, "eks", "list-clusters", "--region", "us-west-2").Return(`{"clusters":[]}`, c.west)
+
+		_, err := New(e).WithOptions(Options{Region: AllRegions, Cluster: "prod", NonInteractive: true}).CreateKubeContext()
+		suite.True(errors.Is(err, c.expectedError), err)
+	}
+}
+
+func (suite ScanSuite) TestCreateKubeContextAllRegionsInterrupted() {
+	for _, stop := range []error{console.ErrCancelled, console.ErrTimeout} {
+		e := new(mocks.Executor)
+		e.On("FindExecutable", executable).Return(executable, nil)
+		e.On("ExecCommand", mock.Anything, executable, "ec2", "describe-regions", "--output", "json").Return(`{"Regions":[{"RegionName":"us-east-1"},{"RegionName":"us-west-2"}]}`, nil)
+		e.On("ExecCommand", mock.Anything, executable, "eks", "list-clusters", "--region", "us-east-1").Return("", fmt.Errorf("%w: aws eks list-clusters was stopped", stop))
+		e.On("ExecCommand", mock.Anything, executable, "eks", "list-clusters", "--region", "us-west-2").Return(`{"clusters":["prod"]}`, nil)
+
+		_, err := New(e).WithOptions(Options{Region: AllRegions, Cluster: "prod", NonInteractive: true, Workers: 1}).CreateKubeContext()
+		suite.True(errors.Is(err, stop), err)
+		e.AssertNotCalled(suite.T(), "Warn", mock.Anything)
+		e.AssertNotCalled(suite.T(), "ExecCommand", mock.Anything, executable, "eks", "list-clusters", "--region", "us-west-2")
+		e.AssertNotCalled(suite.T(), "ExecCommand", mock.Anything, executable, "eks", "update-kubeconfig", "--region", "us-west-2", "--name", "prod")
+	}
+}
+
+func (suite ScanSuite) TestFindCluster() {
+	clusters := []clusterRef{{region: "us-east-1", name: "prod"}, {region: "us-west-2", name: "prod"}, {region: "us-west-2", name: "dev"}}
+
+	res, err := findCluster(clusters, "dev")
+	suite.NoError(err)
+	suite.Equal(clusterRef{region: "us-west-2", name: "dev"}, res)
+
+	res, err = findCluster(clusters, "us-east-1/prod")
+	suite.NoError(err)
+	suite.Equal(clusterRef{region: "us-east-1", name: "prod"}, res)
+
+	_, err = findCluster(clusters, "prod")
+	suite.True(errors.Is(err, ErrAmbiguousCluster))
+
+	_, err = findCluster(clusters, "stage")
+	suite.True(errors.Is(err, ErrClusterNotFound))
+}
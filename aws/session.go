@@ -0,0 +1,60 @@
+package aws
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/eiladin/ekalias/console"
+	"github.com/eiladin/ekalias/sso"
+)
+
+var ErrSSOSessionExpired = errors.New("sso session expired or missing")
+
+// ssoCacheDir is replaced in tests.
+var ssoCacheDir = sso.CacheDir
+
+// ensureSSOSession checks the token cache for the selected profile's sso
+// session and offers to run `aws sso login` when it has expired. Profiles
+// that do not use sso are left alone. The errors returned are permanent, as
+// retrying cannot succeed until the user logs in.
+func (aws AWS) ensureSSOSession() error {
+	name := aws.profileName()
+	if name == "" {
+		name = "default"
+	}
+	p, err := FindProfile(name)
+	if err != nil || p.SSOStartURL == "" {
+		return nil
+	}
+
+	if _, err := sso.CachedToken(ssoCacheDir(), p.SSOStartURL, time.Now()); err == nil {
+		return nil
+	}
+
+	expired := fmt.Errorf("%w for profile %s, run `aws sso login --profile %s`", ErrSSOSessionExpired, name, name)
+	if aws.opts.NonInteractive {
+		return console.Permanent(expired)
+	}
+
+	r, err := aws.executor.PromptInput(fmt.Sprintf("SSO session for profile %s has expired. Run aws sso login? (only 'yes' will be accepted to approve): ", name))
+	if err != nil {
+		return console.Permanent(err)
+	}
+	if r != "yes" {
+		return console.Permanent(expired)
+	}
+
+	cli, err := aws.FindCli()
+	if err != nil {
+		return console.Permanent(err)
+	}
+	if err := aws.executor.ExecInteractive(aws.ctx, cli, "sso", "login", "--profile", name); err != nil {
+		return console.Permanent(err)
+	}
+
+	if _, err := sso.CachedToken(ssoCacheDir(), p.SSOStartURL, time.Now()); err != nil {
+		return console.Permanent(expired)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+//go:build test
+// +build test
+
+package aws
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eiladin/ekalias/console"
+	"github.com/eiladin/ekalias/mocks"
+	"github.com/eiladin/ekalias/sso"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type SessionSuite struct {
+	suite.Suite
+	cacheDir string
+}
+
+func TestSessionSuite(t *testing.T) {
+	suite.Run(t, new(SessionSuite))
+}
+
+func (suite *SessionSuite) SetupTest() {
+	dir, err := ioutil.TempDir("", "ekalias-sso-cache")
+	suite.NoError(err)
+	suite.cacheDir = dir
+	ssoCacheDir = func() string { return dir }
+	os.Setenv("AWS_CONFIG_FILE", filepath.Join("testdata", "config"))
+	os.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join("testdata", "credentials"))
+}
+
+func (suite *SessionSuite) TearDownTest() {
+	os.RemoveAll(suite.cacheDir)
+	ssoCacheDir = sso.CacheDir
+	os.Unsetenv("AWS_CONFIG_FILE")
+	os.Unsetenv("AWS_SHARED_CREDENTIALS_FILE")
+}
+
+func (suite *SessionSuite) cacheToken(startURL string, expires time.Time) {
+	token := fmt.Sprintf(`{"startUrl": %q, "accessToken": "token", "expiresAt": %q}`, startURL, expires.UTC().Format(time.RFC3339))
+	suite.NoError(ioutil.WriteFile(filepath.Join(suite.cacheDir, "token.json"), []byte(token), 0600))
+}
+
+func (suite *SessionSuite) TestEnsureSSOSessionValid() {
+	suite.cacheToken("https://corp.awsapps.com/start", time.Now().Add(time.Hour))
+	e := new(mocks.Executor)
+
+	suite.NoError(New(e).WithOptions(Options{Profile: "prod"}).ensureSSOSession())
+	suite.NoError(New(e).WithOptions(Options{Profile: "dev"}).ensureSSOSession())
+	suite.NoError(New(e).WithOptions(Options{Profile: "missing"}).ensureSSOSession())
+	e.AssertNotCalled(suite.T(), "PromptInput", mock.Anything)
+}
+
+func (suite *SessionSuite) TestEnsureSSOSessionNonInteractive() {
+	suite.cacheToken("https://corp.awsapps.com/start", time.Now().Add(-time.Hour))
+	e := new(mocks.Executor)
+
+	err := New(e).WithOptions(Options{Profile: "prod", NonInteractive: true}).ensureSSOSession()
+	suite.True(errors.Is(err, ErrSSOSessionExpired))
+	suite.Contains(err.Error(), "aws sso login --profile prod")
+	e.AssertNotCalled(suite.T(), "PromptInput", mock.Anything)
+}
+
+func (suite *SessionSuite) TestEnsureSSOSessionLogin() {
+	cases := []struct {
+		answer        string
+		loginError    error
+		login         bool
+		expectedError error
+	}{
+		{answer: "yes", login: true},
+		{answer: "no", expectedError: ErrSSOSessionExpired},
+		{answer: "yes", loginError: errors.New("login failed"), expectedError: errors.New("login failed")},
+		{answer: "yes", expectedError: ErrSSOSessionExpired},
+	}
+
+	for _, c := range cases {
+		os.Remove(filepath.Join(suite.cacheDir, "token.json"))
+		e := new(mocks.Executor)
+		e.On("PromptInput", "SSO session for profile legacy-sso has expired. Run aws sso login? (only 'yes' will be accepted to approve): ").Return(c.answer, nil)
+		e.On("FindExecutable", executable).Return(executable, nil)
+		e.On("ExecInteractive", mock.Anything, executable, "sso", "login", "--profile", "legacy-sso").Return(c.loginError).Run(func(mock.Arguments) {
+			if c.login {
+				suite.cacheToken("https://legacy.awsapps.com/start", time.Now().Add(time.Hour))
+			}
+		})
+
+		err := New(e).WithOptions(Options{Profile: "legacy-sso"}).ensureSSOSession()
+		if c.expectedError == nil {
+			suite.NoError(err)
+			e.AssertCalled(suite.T(), "ExecInteractive", mock.Anything, executable, "sso", "login", "--profile", "legacy-sso")
+			continue
+		}
+
+		suite.Error(err)
+		suite.Contains(err.Error(), c.expectedError.Error())
+
+		// the "Create New" loop gives up instead of retrying
+		calls := 0
+		_, err = console.New(strings.NewReader("2\n"), ioutil.Discard, ioutil.Discard).SelectValueFromList([]string{"a"}, "Kube Context", func() (string, error) {
+			calls++
+			return "", err
+		})
+		suite.Error(err)
+		suite.Equal(1, calls)
+	}
+}
@@ -0,0 +1,319 @@
+package aws
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/eiladin/ekalias/shell"
+	"github.com/eiladin/ekalias/sso"
+)
+
+// DefaultProfileTemplate names synced profiles after the account and role.
+const DefaultProfileTemplate = "{{.AccountName}}-{{.RoleName}}"
+
+var ErrDuplicateProfile = errors.New("profile name template produced the same name twice, include {{.AccountID}} in --name-template")
+
+// SSOClient lists the accounts and roles available through the sso portal.
+type SSOClient interface {
+	Accounts() ([]sso.Account, error)
+	Roles(accountID string) ([]sso.Role, error)
+}
+
+// SyncOptions configures how sso profiles are discovered and named.
+type SyncOptions struct {
+	StartURL     string
+	SSORegion    string
+	Region       string
+	NameTemplate string
+	Workers      int
+}
+
+// ProfileName holds the values available to the profile name template.
+type ProfileName struct {
+	AccountID   string
+	AccountName string
+	RoleName    string
+}
+
+// SSOProfiles returns a profile for every account and role the sso token can
+// access, named with opts.NameTemplate. Roles are listed concurrently.
+func SSOProfiles(client SSOClient, opts SyncOptions) ([]Profile, error) {
+	name, err := profileNamer(opts.NameTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts, err := client.Accounts()
+	if err != nil {
+		return nil, err
+	}
+
+	roles := make([][]sso.Role, len(accounts))
+	errs := make([]error, len(accounts))
+	parallel(len(accounts), opts.Workers, func(i int) {
+		roles[i], errs[i] = client.Roles(accounts[i].ID)
+	})
+
+	var profiles []Profile
+	seen := map[string]bool{}
+	for i, account := range accounts {
+		if errs[i] != nil {
+			return nil, fmt.Errorf("listing roles for %s: %w", account.ID, errs[i])
+		}
+		for _, role := range roles[i] {
+			n, err := name(ProfileName{AccountID: account.ID, AccountName: account.Name, RoleName: role.Name})
+			if err != nil {
+				return nil, err
+			}
+			if seen[n] {
+				return nil, fmt.Errorf("%w: %s", ErrDuplicateProfile, n)
+			}
+			seen[n] = true
+			profiles = append(profiles, Profile{
+				Name:         n,
+				Region:       opts.Region,
+				SSOStartURL:  opts.StartURL,
+				SSORegion:    opts.SSORegion,
+				SSOAccountID: account.ID,
+				SSORoleName:  role.Name,
+			})
+		}
+	}
+
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].Name < profiles[j].Name })
+	return profiles, nil
+}
+
+// profileNamer parses the profile name template.
+func profileNamer(text string) (func(ProfileName) (string, error), error) {
+	if text == "" {
+		text = DefaultProfileTemplate
+	}
+	name, err := shell.NewNamer("profile", text)
+	if err != nil {
+		return nil, err
+	}
+	return func(p ProfileName) (string, error) {
+		n, err := name(p)
+		if err != nil {
+			return "", fmt.Errorf("%w for account %s", err, p.AccountID)
+		}
+		return n, nil
+	}, nil
+}
+
+// UpdateConfig adds or updates a profile block for every profile in the
+// contents of a shared config file. Keys ekalias does not manage are kept.
+func UpdateConfig(config string, profiles []Profile) string {
+	lines := strings.Split(strings.TrimRight(config, "\n"), "\n")
+	if config == "" {
+		lines = nil
+	}
+
+	for _, p := range profiles {
+		values := [][2]string{
+			{"sso_start_url", p.SSOStartURL},
+			{"sso_region", p.SSORegion},
+			{"sso_account_id", p.SSOAccountID},
+			{"sso_role_name", p.SSORoleName},
+		}
+		if p.Region != "" {
+			values = append(values, [2]string{"region", p.Region})
+		}
+
+		header := "profile " + p.Name
+		if p.Name == "default" {
+			header = "default"
+		}
+		lines = setSection(lines, header, values)
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func setSection(lines []string, header string, values [][2]string) []string {
+	start := -1
+	for i, line := range lines {
+		if sectionName(line) == header {
+			start = i
+			break
+		}
+	}
+
+	if start < 0 {
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, "["+header+"]")
+		for _, v := range values {
+			lines = append(lines, v[0]+" = "+v[1])
+		}
+		return lines
+	}
+
+	end := len(lines)
+	for i := start + 1; i < len(lines); i++ {
+		if sectionName(lines[i]) != "" {
+			end = i
+			break
+		}
+	}
+	// keep the blank lines between sections after the added keys
+	for end > start+1 && strings.TrimSpace(lines[end-1]) == "" {
+		end--
+	}
+
+	section := append([]string{}, lines[start:end]...)
+	for _, v := range values {
+		set := false
+		for i, line := range section {
+			if line != "" && line[0] != ' ' && line[0] != '\t' && configKey(line) == v[0] {
+				section[i] = v[0] + " = " + v[1]
+				set = true
+				break
+			}
+		}
+		if !set {
+			section = append(section, v[0]+" = "+v[1])
+		}
+	}
+
+	// sso_session would take precedence over the keys set above
+	kept := section[:0]
+	for _, line := range section {
+		if configKey(line) != "sso_session" {
+			kept = append(kept, line)
+		}
+	}
+
+	out := append([]string{}, lines[:start]...)
+	out = append(out, kept...)
+	return append(out, lines[end:]...)
+}
+
+func sectionName(line string) string {
+	line = strings.TrimSpace(line)
+	if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+		return strings.Join(strings.Fields(line[1:len(line)-1]), " ")
+	}
+	return ""
+}
+
+func configKey(line string) string {
+	parts := strings.SplitN(line, "=", 2)
+	if len(parts) != 2 {
+		return ""
+	}
+	return strings.TrimSpace(parts[0])
+}
+
+// SyncConfig adds the profiles to the shared config file at path and returns
+// the diff of the change. With dryRun the file is left untouched. The previous
+// file is kept next to it with a .ekalias.bak extension.
+func SyncConfig(path string, profiles []Profile, dryRun bool) (string, error) {
+	before := ""
+	data, err := ioutil.ReadFile(path)
+	if err == nil {
+		before = string(data)
+	} else if !os.IsNotExist(err) {
+		return "", err
+	}
+
+	after := UpdateConfig(before, profiles)
+	diff := Diff(before, after)
+	if dryRun || diff == "" {
+		return diff, nil
+	}
+
+	mode := os.FileMode(0600)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+		if err := ioutil.WriteFile(path+".ekalias.bak", data, mode); err != nil {
+			return "", err
+		}
+	} else if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return "", err
+	}
+	return diff, ioutil.WriteFile(path, []byte(after), mode)
+}
+
+// Diff returns the lines that differ between two versions of a file, prefixed
+// with "-" or "+", with unchanged lines around each change for context.
+func Diff(before, after string) string {
+	a := splitLines(before)
+	b := splitLines(after)
+
+	// longest common subsequence, small files only
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	type op struct {
+		kind byte
+		line string
+	}
+	var ops []op
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			ops = append(ops, op{' ', a[i]})
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, op{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, op{'+', b[j]})
+			j++
+		}
+	}
+
+	const context = 2
+	show := make([]bool, len(ops))
+	for k, o := range ops {
+		if o.kind == ' ' {
+			continue
+		}
+		for c := k - context; c <= k+context; c++ {
+			if c >= 0 && c < len(ops) {
+				show[c] = true
+			}
+		}
+	}
+
+	var out strings.Builder
+	for k, o := range ops {
+		if !show[k] {
+			continue
+		}
+		if k > 0 && !show[k-1] {
+			out.WriteString("...\n")
+		}
+		out.WriteString(string(o.kind) + " " + o.line + "\n")
+	}
+	return out.String()
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimRight(s, "\n"), "\n")
+}
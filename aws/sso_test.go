@@ -0,0 +1,153 @@
+//go:build test
+// +build test
+
+package aws
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/eiladin/ekalias/sso"
+	"github.com/stretchr/testify/suite"
+)
+
+type SSOSuite struct {
+	suite.Suite
+}
+
+func TestSSOSuite(t *testing.T) {
+	suite.Run(t, new(SSOSuite))
+}
+
+type fakeSSO struct {
+	accounts []sso.Account
+	roles    map[string][]string
+	err      error
+}
+
+func (f fakeSSO) Accounts() ([]sso.Account, error) {
+	return f.accounts, nil
+}
+
+func (f fakeSSO) Roles(accountID string) ([]sso.Role, error) {
+	var roles []sso.Role
+	for _, r := range f.roles[accountID] {
+		roles = append(roles, sso.Role{AccountID: accountID, Name: r})
+	}
+	return roles, f.err
+}
+
+var testSSO = fakeSSO{
+	accounts: []sso.Account{
+		{ID: "111111111111", Name: "Shared Services"},
+		{ID: "222222222222", Name: "prod"},
+	},
+	roles: map[string][]string{
+		"111111111111": {"ReadOnly"},
+		"222222222222": {"AdministratorAccess", "ReadOnly"},
+	},
+}
+
+func (suite SSOSuite) TestSSOProfiles() {
+	opts := SyncOptions{StartURL: "https://corp.awsapps.com/start", SSORegion: "us-east-2", Region: "us-west-2"}
+	profiles, err := SSOProfiles(testSSO, opts)
+	suite.NoError(err)
+	suite.Equal([]Profile{
+		{Name: "Shared-Services-ReadOnly", Region: "us-west-2", SSOStartURL: opts.StartURL, SSORegion: "us-east-2", SSOAccountID: "111111111111", SSORoleName: "ReadOnly"},
+		{Name: "prod-AdministratorAccess", Region: "us-west-2", SSOStartURL: opts.StartURL, SSORegion: "us-east-2", SSOAccountID: "222222222222", SSORoleName: "AdministratorAccess"},
+		{Name: "prod-ReadOnly", Region: "us-west-2", SSOStartURL: opts.StartURL, SSORegion: "us-east-2", SSOAccountID: "222222222222", SSORoleName: "ReadOnly"},
+	}, profiles)
+}
+
+func (suite SSOSuite) TestSSOProfilesNameTemplate() {
+	cases := []struct {
+		template      string
+		expected      []string
+		expectedError error
+	}{
+		{template: "{{.AccountName | lower}}.{{.RoleName | lower}}", expected: []string{"prod.administratoraccess", "prod.readonly", "shared-services.readonly"}},
+		{template: "{{.AccountID}}/{{.RoleName}}", expected: []string{"111111111111-ReadOnly", "222222222222-AdministratorAccess", "222222222222-ReadOnly"}},
+		{template: "{{.AccountName}}", expectedError: ErrDuplicateProfile},
+	}
+
+	for _, c := range cases {
+		profiles, err := SSOProfiles(testSSO, SyncOptions{NameTemplate: c.template})
+		if c.expectedError != nil {
+			suite.True(errors.Is(err, c.expectedError), c.template)
+			continue
+		}
+		suite.NoError(err, c.template)
+		var names []string
+		for _, p := range profiles {
+			names = append(names, p.Name)
+		}
+		suite.Equal(c.expected, names, c.template)
+	}
+
+	_, err := SSOProfiles(testSSO, SyncOptions{NameTemplate: "{{.Missing"})
+	suite.Error(err)
+	_, err = SSOProfiles(testSSO, SyncOptions{NameTemplate: "  "})
+	suite.Error(err)
+}
+
+func (suite SSOSuite) TestSSOProfilesRolesError() {
+	f := testSSO
+	f.err = errors.New("forbidden")
+	_, err := SSOProfiles(f, SyncOptions{})
+	suite.EqualError(err, "listing roles for 111111111111: forbidden")
+}
+
+func (suite SSOSuite) TestUpdateConfig() {
+	config := "[default]\nregion = us-east-1\n\n[profile prod]\nsso_session = corp\nsso_role_name = ReadOnly\noutput = json\n\n[sso-session corp]\nsso_start_url = https://corp.awsapps.com/start\n"
+	profiles := []Profile{
+		{Name: "prod", SSOStartURL: "https://corp.awsapps.com/start", SSORegion: "us-east-2", SSOAccountID: "222222222222", SSORoleName: "AdministratorAccess"},
+		{Name: "dev", Region: "us-west-2", SSOStartURL: "https://corp.awsapps.com/start", SSORegion: "us-east-2", SSOAccountID: "111111111111", SSORoleName: "ReadOnly"},
+	}
+
+	expected := "[default]\nregion = us-east-1\n\n[profile prod]\nsso_role_name = AdministratorAccess\noutput = json\nsso_start_url = https://corp.awsapps.com/start\nsso_region = us-east-2\nsso_account_id = 222222222222\n\n[sso-session corp]\nsso_start_url = https://corp.awsapps.com/start\n\n[profile dev]\nsso_start_url = https://corp.awsapps.com/start\nsso_region = us-east-2\nsso_account_id = 111111111111\nsso_role_name = ReadOnly\nregion = us-west-2\n"
+	updated := UpdateConfig(config, profiles)
+	suite.Equal(expected, updated)
+	suite.Equal(updated, UpdateConfig(updated, profiles))
+
+	suite.Equal("[profile dev]\nsso_start_url = \nsso_region = \nsso_account_id = 1\nsso_role_name = r\n", UpdateConfig("", []Profile{{Name: "dev", SSOAccountID: "1", SSORoleName: "r"}}))
+}
+
+func (suite SSOSuite) TestDiff() {
+	suite.Equal("", Diff("a\nb\n", "a\nb\n"))
+	suite.Equal("+ a\n+ b\n", Diff("", "a\nb\n"))
+	suite.Equal("  1\n  2\n- 3\n+ three\n  4\n  5\n...\n  8\n  9\n+ 10\n", Diff("1\n2\n3\n4\n5\n6\n7\n8\n9\n", "1\n2\nthree\n4\n5\n6\n7\n8\n9\n10\n"))
+}
+
+func (suite SSOSuite) TestSyncConfig() {
+	dir, err := ioutil.TempDir("", "ekalias-sso")
+	suite.NoError(err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, ".aws", "config")
+	profiles := []Profile{{Name: "dev", SSOStartURL: "https://corp.awsapps.com/start", SSORegion: "us-east-2", SSOAccountID: "1", SSORoleName: "ReadOnly"}}
+
+	diff, err := SyncConfig(path, profiles, true)
+	suite.NoError(err)
+	suite.Contains(diff, "+ [profile dev]")
+	suite.NoFileExists(path)
+
+	diff, err = SyncConfig(path, profiles, false)
+	suite.NoError(err)
+	suite.Contains(diff, "+ [profile dev]")
+	suite.FileExists(path)
+	suite.NoFileExists(path + ".ekalias.bak")
+
+	diff, err = SyncConfig(path, profiles, false)
+	suite.NoError(err)
+	suite.Equal("", diff)
+
+	profiles[0].SSORoleName = "AdministratorAccess"
+	diff, err = SyncConfig(path, profiles, false)
+	suite.NoError(err)
+	suite.Contains(diff, "- sso_role_name = ReadOnly\n+ sso_role_name = AdministratorAccess\n")
+	backup, err := ioutil.ReadFile(path + ".ekalias.bak")
+	suite.NoError(err)
+	suite.Contains(string(backup), "sso_role_name = ReadOnly")
+}
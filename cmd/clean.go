@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+type cleanCmd struct {
+	cmd    *cobra.Command
+	dryRun bool
+}
+
+func newCleanCmd() *cleanCmd {
+	var root = &cleanCmd{}
+	var cmd = &cobra.Command{
+		Use:   "clean",
+		Short: "remove isolated kubeconfig files that no alias uses",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			r, err := loadRegistry()
+			if err != nil {
+				return err
+			}
+
+			orphans, err := r.Orphans()
+			if err != nil {
+				return err
+			}
+
+			for _, f := range orphans {
+				if root.dryRun {
+					fmt.Printf("Would remove %s\n", f)
+					continue
+				}
+				if err := os.Remove(f); err != nil {
+					return err
+				}
+				fmt.Printf("Removed %s\n", f)
+			}
+			if len(orphans) == 0 {
+				fmt.Println("Nothing to clean")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&root.dryRun, "dry-run", false, "list the files without removing them")
+
+	root.cmd = cmd
+	return root
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/eiladin/ekalias/aws"
+	"github.com/eiladin/ekalias/console"
+	"github.com/eiladin/ekalias/sso"
+	"github.com/logrusorgru/aurora/v3"
+)
+
+// Exit codes, so scripts can tell failures apart.
+const (
+	exitOK           = 0
+	exitError        = 1
+	exitCliNotFound  = 3
+	exitCliFailed    = 4
+	exitAuthExpired  = 5
+	exitNoClusters   = 6
+	exitAccessDenied = 7
+	exitTimeout      = 8
+	exitCancelled    = 130
+)
+
+// exitStatus ends ekalias with the given status without printing anything,
+// for commands such as exec that pass on the status of another command.
+type exitStatus int
+
+func (s exitStatus) Error() string { return fmt.Sprintf("exit status %d", int(s)) }
+
+// exitCode returns the process exit code for err.
+func exitCode(err error) int {
+	var status exitStatus
+	var cmdErr *console.CommandError
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.As(err, &status):
+		return int(status)
+	case errors.Is(err, console.ErrCancelled):
+		return exitCancelled
+	case errors.Is(err, console.ErrTimeout):
+		return exitTimeout
+	case errors.Is(err, aws.ErrSSOSessionExpired), errors.Is(err, aws.ErrCredentialsExpired), errors.Is(err, sso.ErrNoToken):
+		return exitAuthExpired
+	case errors.Is(err, aws.ErrNoClusters):
+		return exitNoClusters
+	case errors.Is(err, aws.ErrAccessDenied):
+		return exitAccessDenied
+	case errors.Is(err, console.ErrCliNotFound):
+		return exitCliNotFound
+	case errors.As(err, &cmdErr):
+		return exitCliFailed
+	default:
+		return exitError
+	}
+}
+
+// hint returns what the user can do about err, if there is something more
+// to say than the error itself.
+func hint(err error) string {
+	switch exitCode(err) {
+	case exitCliNotFound:
+		return "install it, or add the directory it is in to your PATH"
+	case exitNoClusters:
+		return fmt.Sprintf("check the profile and region, or use --region %s to look in every region", aws.AllRegions)
+	case exitCliFailed:
+		return "run again with --verbose to see the output of every command"
+	}
+	return ""
+}
+
+// printError writes err and its hint to w. exitStatus errors are not
+// printed.
+func printError(w io.Writer, err error) {
+	var status exitStatus
+	switch {
+	case err == nil, errors.As(err, &status):
+		return
+	case errors.Is(err, console.ErrCancelled):
+		fmt.Fprintln(w, "Cancelled")
+		return
+	}
+	fmt.Fprintln(w, aurora.Red(fmt.Sprintf("Error: %s", err)))
+	if h := hint(err); h != "" {
+		fmt.Fprintln(w, h)
+	}
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/eiladin/ekalias/aws"
+	"github.com/eiladin/ekalias/console"
+	"github.com/eiladin/ekalias/sso"
+	"github.com/stretchr/testify/suite"
+)
+
+type ErrorsSuite struct {
+	suite.Suite
+}
+
+func TestErrorsSuite(t *testing.T) {
+	suite.Run(t, new(ErrorsSuite))
+}
+
+func (suite ErrorsSuite) TestExitCode() {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	cmdErr := &console.CommandError{Name: "aws", Args: []string{"eks", "list-clusters"}, ExitCode: 255, Stderr: "boom"}
+
+	cases := []struct {
+		err      error
+		expected int
+	}{
+		{err: nil, expected: exitOK},
+		{err: errors.New("boom"), expected: exitError},
+		{err: exitStatus(0), expected: 0},
+		{err: exitStatus(42), expected: 42},
+		{err: fmt.Errorf("exec: %w", exitStatus(2)), expected: 2},
+		{err: console.ErrCancelled, expected: exitCancelled},
+		{err: console.Interrupted(cancelled, "aws eks list-clusters"), expected: exitCancelled},
+		{err: console.ErrTimeout, expected: exitTimeout},
+		{err: fmt.Errorf("%w: aws sts get-caller-identity", console.ErrTimeout), expected: exitTimeout},
+		{err: aws.ErrSSOSessionExpired, expected: exitAuthExpired},
+		{err: aws.ErrCredentialsExpired, expected: exitAuthExpired},
+		{err: fmt.Errorf("%w for https://example.awsapps.com/start", sso.ErrNoToken), expected: exitAuthExpired},
+		{err: aws.ErrNoClusters, expected: exitNoClusters},
+		{err: fmt.Errorf("profile dev: %w", aws.ErrAccessDenied), expected: exitAccessDenied},
+		{err: fmt.Errorf("%w: kubectl", console.ErrCliNotFound), expected: exitCliNotFound},
+		{err: cmdErr, expected: exitCliFailed},
+		{err: fmt.Errorf("unable to create kube context: %w", cmdErr), expected: exitCliFailed},
+	}
+
+	for _, c := range cases {
+		suite.Equal(c.expected, exitCode(c.err), fmt.Sprint(c.err))
+	}
+}
+
+func (suite ErrorsSuite) TestPrintError() {
+	cases := []struct {
+		err      error
+		contains []string
+		empty    bool
+	}{
+		{err: nil, empty: true},
+		{err: exitStatus(3), empty: true},
+		{err: fmt.Errorf("stopped: %w", console.ErrCancelled), contains: []string{"Cancelled\n"}},
+		{err: fmt.Errorf("%w: kubectl", console.ErrCliNotFound), contains: []string{"Error: command not found on PATH: kubectl", "add the directory it is in to your PATH"}},
+		{err: aws.ErrNoClusters, contains: []string{"Error: " + aws.ErrNoClusters.Error(), "--region " + aws.AllRegions}},
+		{err: &console.CommandError{Name: "aws", ExitCode: 1}, contains: []string{"Error: aws failed with exit code 1", "--verbose"}},
+		{err: errors.New("boom"), contains: []string{"Error: boom"}},
+	}
+
+	for _, c := range cases {
+		var out bytes.Buffer
+		printError(&out, c.err)
+		if c.empty {
+			suite.Empty(out.String(), fmt.Sprint(c.err))
+			continue
+		}
+		for _, s := range c.contains {
+			suite.Contains(out.String(), s, fmt.Sprint(c.err))
+		}
+	}
+
+	var out bytes.Buffer
+	printError(&out, errors.New("boom"))
+	suite.Equal(1, bytes.Count(out.Bytes(), []byte("\n")), "no hint for other errors")
+}
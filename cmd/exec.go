@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/eiladin/ekalias/console"
+	"github.com/eiladin/ekalias/kubeconfig"
+	"github.com/eiladin/ekalias/registry"
+	"github.com/spf13/cobra"
+)
+
+type execCmd struct {
+	cmd *cobra.Command
+}
+
+func newExecCmd() *execCmd {
+	var root = &execCmd{}
+	var cmd = &cobra.Command{
+		Use:   "exec <alias> -- <command> [args...]",
+		Short: "run a command with the profile and context of an alias, without switching your shell",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(commandArgs(args)) == 0 {
+				return errors.New("alias and command required")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			r, err := loadRegistry()
+			if err != nil {
+				return err
+			}
+			a, err := r.Get(args[0])
+			if err != nil {
+				return err
+			}
+
+			command := commandArgs(args)
+			return execAlias(cmd.Context(), newExecutor(cmd), a, command[0], command[1:])
+		},
+	}
+	// everything after the alias belongs to the command
+	cmd.Flags().SetInterspersed(false)
+
+	root.cmd = cmd
+	return root
+}
+
+// commandArgs returns the command following the alias. Flag parsing stops at
+// the alias, so cobra leaves the "--" separator in place.
+func commandArgs(args []string) []string {
+	if len(args) < 2 {
+		return nil
+	}
+	if args[1] == "--" {
+		return args[2:]
+	}
+	return args[1:]
+}
+
+// execAlias runs name with AWS_PROFILE, KUBECONFIG and env set for the
+// child process only. A command that exits with a non-zero code returns it
+// as an exitStatus. Aliases without an isolated kubeconfig get a temporary
+// one holding their context and namespace.
+func execAlias(ctx context.Context, e console.Executor, a registry.Alias, name string, args []string, env ...string) error {
+	path, err := e.FindExecutable(name)
+	if err != nil {
+		return err
+	}
+
+	kubeconfigPath := a.Kubeconfig
+	if kubeconfigPath == "" {
+		dir, err := ioutil.TempDir("", "ekalias-exec")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(dir)
+
+		kubeconfigPath = filepath.Join(dir, "config")
+		if err := kubeconfig.Extract(a.Context, a.Namespace, kubeconfigPath); err != nil {
+			return fmt.Errorf("unable to write kubeconfig: %w", err)
+		}
+	}
+
+	env = append([]string{"AWS_PROFILE=" + a.Profile, "KUBECONFIG=" + kubeconfigPath}, env...)
+	err = e.WithEnv(env...).ExecInteractive(ctx, path, args...)
+
+	var cmdErr *console.CommandError
+	if errors.As(err, &cmdErr) {
+		return exitStatus(cmdErr.ExitCode)
+	}
+	return err
+}
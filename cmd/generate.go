@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/eiladin/ekalias/aws"
+	"github.com/eiladin/ekalias/registry"
+	"github.com/eiladin/ekalias/shell"
+	"github.com/logrusorgru/aurora/v3"
+	"github.com/spf13/cobra"
+)
+
+type generateAllCmd struct {
+	cmd      *cobra.Command
+	install  bool
+	isolated bool
+	force    bool
+	shell    string
+	aws      aws.Options
+	opts     aws.GenerateOptions
+}
+
+func newGenerateAllCmd() *generateAllCmd {
+	var root = &generateAllCmd{}
+	var cmd = &cobra.Command{
+		Use:   "generate-all",
+		Short: "generate an alias for every EKS cluster of every AWS profile",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sh := root.shell
+			if sh == "" {
+				sh = shell.Detect(os.Getenv("SHELL"))
+			}
+			renderer, err := shell.NewRenderer(sh)
+			if err != nil {
+				return err
+			}
+			for _, r := range root.opts.Regions {
+				if err := aws.ValidateRegion(r); err != nil {
+					return err
+				}
+				if r == aws.AllRegions {
+					root.opts.Regions = nil
+					break
+				}
+			}
+
+			r, err := loadRegistry()
+			if err != nil {
+				return err
+			}
+
+			executor := newExecutor(cmd)
+			root.opts.Workers = root.aws.Workers
+			root.aws.Timeout = commandTimeout(cmd)
+			// names are checked before their kube context is created, so an
+			// unusable name does not leave a context behind
+			root.opts.Validate = func(name string) error {
+				if err := checkAliasName(renderer, executor, name, root.force); err != nil {
+					return err
+				}
+				if _, err := r.Get(name); err == nil && !root.force {
+					return fmt.Errorf("%w: %s, use --force to replace it", registry.ErrExists, name)
+				}
+				return nil
+			}
+			// when stopped, the aliases of the kube contexts created so far
+			// are still recorded
+			results, stopped := aws.New(executor).WithOptions(root.aws).WithContext(cmd.Context()).GenerateAll(root.opts)
+			if stopped != nil && len(results) == 0 {
+				return stopped
+			}
+
+			var failed []aws.Generated
+			var l leftovers
+			generated := 0
+			record := func(prev, entry registry.Alias) {
+				l.add(prev, entry)
+				r.Put(entry)
+				generated++
+			}
+			// installed aliases are written to the rc file together after the
+			// loop, so it is backed up once
+			type pending struct {
+				g     aws.Generated
+				prev  registry.Alias
+				entry registry.Alias
+			}
+			var installs []pending
+			var definitions []shell.Definition
+			for _, g := range results {
+				if g.Err != nil {
+					failed = append(failed, g)
+					continue
+				}
+
+				entry := registry.Alias{
+					Name:      g.Alias,
+					Profile:   g.Profile,
+					Context:   g.Context,
+					Region:    g.Region,
+					Shell:     sh,
+					CreatedAt: time.Now(),
+				}
+				if root.isolated {
+					if entry.Kubeconfig, err = isolate(r, g.Alias, g.Context, ""); err != nil {
+						failed = append(failed, aws.Generated{Profile: g.Profile, Region: g.Region, Cluster: g.Cluster, Err: err})
+						continue
+					}
+				}
+
+				alias := renderer.Render(shell.Alias{Name: g.Alias, Profile: g.Profile, Context: g.Context, Kubeconfig: entry.Kubeconfig})
+				fmt.Println(aurora.Green(alias))
+
+				if arn, ok := clusterARN(g.Context); ok {
+					entry.ClusterARN = arn.String()
+				}
+				// a replaced alias that was installed stays installed
+				prev, _ := r.Get(g.Alias)
+				if root.install || prev.RcFile != "" {
+					installs = append(installs, pending{g: g, prev: prev, entry: entry})
+					definitions = append(definitions, shell.Definition{Name: g.Alias, Text: alias})
+					continue
+				}
+				record(prev, entry)
+			}
+
+			if len(installs) > 0 {
+				rcFile, err := installAliases(sh, definitions)
+				for _, p := range installs {
+					if err != nil {
+						failed = append(failed, aws.Generated{Profile: p.g.Profile, Region: p.g.Region, Cluster: p.g.Cluster, Err: err})
+						continue
+					}
+					p.entry.RcFile = rcFile
+					record(p.prev, p.entry)
+				}
+			}
+
+			if err := l.remove(); err != nil {
+				return fmt.Errorf("unable to replace aliases: %w", err)
+			}
+			if err := r.Save(); err != nil {
+				return fmt.Errorf("unable to save aliases: %w", err)
+			}
+
+			fmt.Printf("\nGenerated %d aliases, %d failed\n", generated, len(failed))
+			for _, g := range failed {
+				where := strings.Trim(strings.Join([]string{g.Profile, g.Region, g.Cluster}, "/"), "/")
+				fmt.Println(aurora.Red(fmt.Sprintf("  %s: %s", where, g.Err.Error())))
+			}
+			if stopped != nil {
+				return stopped
+			}
+			if len(failed) > 0 {
+				return exitStatus(exitError)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&root.opts.Profiles, "profile", nil, "AWS profiles to scan, every profile by default")
+	cmd.Flags().StringSliceVar(&root.opts.Regions, "region", nil, "regions to scan, every enabled region by default")
+	cmd.Flags().StringVar(&root.opts.Template, "template", aws.DefaultAliasTemplate, "alias name template, with .Profile, .Region, .Cluster and the lower and upper functions")
+	cmd.Flags().IntVar(&root.aws.Workers, "workers", 8, "number of profiles and regions to query at once")
+	cmd.Flags().StringVar(&root.aws.Backend, "backend", aws.BackendCLI, fmt.Sprintf("how to discover EKS clusters: %s (aws cli) or %s (EKS API)", aws.BackendCLI, aws.BackendAPI))
+	cmd.Flags().StringVar(&root.aws.Endpoint, "eks-endpoint", "", "EKS API endpoint to use with --backend api")
+	cmd.Flags().BoolVar(&root.install, "install", false, "write the aliases to your shell rc file")
+	cmd.Flags().BoolVar(&root.isolated, "isolated", false, "give every alias its own kubeconfig file instead of changing the shared current context")
+	cmd.Flags().BoolVar(&root.force, "force", false, "use alias names that shadow a command on PATH and replace aliases that already exist")
+	cmd.Flags().StringVar(&root.shell, "shell", "", fmt.Sprintf("shell to generate the aliases for (%s), detected from $SHELL by default", strings.Join(shell.Shells(), ", ")))
+
+	root.cmd = cmd
+	return root
+}
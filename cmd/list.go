@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+type listCmd struct {
+	cmd *cobra.Command
+}
+
+func newListCmd() *listCmd {
+	var root = &listCmd{}
+	var cmd = &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "list generated aliases",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			r, err := loadRegistry()
+			if err != nil {
+				return err
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "NAME\tPROFILE\tCONTEXT\tCREATED")
+			for _, a := range r.Aliases {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", a.Name, a.Profile, a.Context, a.CreatedAt.Format("2006-01-02 15:04"))
+			}
+			w.Flush()
+			return nil
+		},
+	}
+
+	root.cmd = cmd
+	return root
+}
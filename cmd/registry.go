@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/eiladin/ekalias/console"
+	"github.com/eiladin/ekalias/kubeconfig"
+	"github.com/eiladin/ekalias/registry"
+	"github.com/eiladin/ekalias/shell"
+	"github.com/spf13/cobra"
+)
+
+var errCommandExists = errors.New("alias name shadows an existing command")
+
+// checkAliasName rejects names the shell cannot use and, unless force is
+// set, names that would shadow a command on PATH such as ls or kubectl.
+func checkAliasName(renderer shell.Renderer, e console.Executor, name string, force bool) error {
+	if err := renderer.Validate(name); err != nil {
+		return err
+	}
+	if force {
+		return nil
+	}
+	if path, err := e.FindExecutable(name); err == nil {
+		return fmt.Errorf("%w: %s is %s, use --force to use it anyway", errCommandExists, name, path)
+	}
+	return nil
+}
+
+// newExecutor returns the executor for cmd, set up with --verbose and
+// --timeout.
+func newExecutor(cmd *cobra.Command) console.Executor {
+	verbose, _ := cmd.Flags().GetBool("verbose")
+	return console.DefaultExecutor{Stdin: os.Stdin, Stdout: os.Stdout, Stderr: os.Stderr, Verbose: verbose, Timeout: commandTimeout(cmd)}
+}
+
+// commandTimeout returns how long each aws and kubectl command, and each
+// API request, of cmd may take.
+func commandTimeout(cmd *cobra.Command) time.Duration {
+	d, _ := cmd.Flags().GetDuration("timeout")
+	return d
+}
+
+func loadRegistry() (*registry.Registry, error) {
+	path, err := registry.DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	return registry.Load(path)
+}
+
+// isolate writes the isolated kubeconfig of an alias to the registry's kube
+// directory and returns its path.
+func isolate(r *registry.Registry, name, context, namespace string) (string, error) {
+	path := r.KubeconfigPath(name)
+	return path, kubeconfig.Extract(context, namespace, path)
+}
+
+// leftovers collects what aliases replaced by new ones of the same name
+// leave behind, so it can be removed in one go.
+type leftovers struct {
+	rcFiles     map[string][]string
+	kubeconfigs []string
+}
+
+// add records the rc file entry of prev, an alias that next replaces, when
+// next is not written to the same file, and its isolated kubeconfig when
+// next does not use it.
+func (l *leftovers) add(prev, next registry.Alias) {
+	if prev.RcFile != "" && prev.RcFile != next.RcFile {
+		if l.rcFiles == nil {
+			l.rcFiles = map[string][]string{}
+		}
+		l.rcFiles[prev.RcFile] = append(l.rcFiles[prev.RcFile], prev.Name)
+	}
+	if prev.Kubeconfig != "" && prev.Kubeconfig != next.Kubeconfig {
+		l.kubeconfigs = append(l.kubeconfigs, prev.Kubeconfig)
+	}
+}
+
+// remove uninstalls the recorded entries, writing each rc file once, and
+// removes the recorded kubeconfig files.
+func (l leftovers) remove() error {
+	for path, names := range l.rcFiles {
+		if err := shell.Uninstall(path, names...); err != nil {
+			return err
+		}
+	}
+	for _, path := range l.kubeconfigs {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func installAlias(sh, name, alias string) (string, error) {
+	return installAliases(sh, []shell.Definition{{Name: name, Text: alias}})
+}
+
+// installAliases writes definitions to the rc file of sh, which is backed up
+// once, and returns its path.
+func installAliases(sh string, definitions []shell.Definition) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	rcFile, err := shell.RcFile(sh, home)
+	if err != nil {
+		return "", err
+	}
+	return rcFile, shell.InstallAll(rcFile, definitions)
+}
+
+// aliasShell returns the shell an alias was generated for.
+func aliasShell(a registry.Alias) string {
+	if a.Shell != "" {
+		return a.Shell
+	}
+	return shell.Detect(os.Getenv("SHELL"))
+}
+
+func renderAlias(a registry.Alias) (string, error) {
+	renderer, err := shell.NewRenderer(aliasShell(a))
+	if err != nil {
+		return "", err
+	}
+	return renderer.Render(shell.Alias{Name: a.Name, Profile: a.Profile, Context: a.Context, Namespace: a.Namespace, Kubeconfig: a.Kubeconfig}), nil
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/eiladin/ekalias/shell"
+	"github.com/spf13/cobra"
+)
+
+type renameCmd struct {
+	cmd   *cobra.Command
+	force bool
+}
+
+func newRenameCmd() *renameCmd {
+	var root = &renameCmd{}
+	var cmd = &cobra.Command{
+		Use:     "rename <old> <new>",
+		Aliases: []string{"mv"},
+		Short:   "rename an alias in the registry and your shell rc file",
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			r, err := loadRegistry()
+			if err != nil {
+				return err
+			}
+
+			old, err := r.Get(args[0])
+			if err != nil {
+				return err
+			}
+			renderer, err := shell.NewRenderer(aliasShell(old))
+			if err != nil {
+				return err
+			}
+			if err := checkAliasName(renderer, newExecutor(cmd), args[1], root.force); err != nil {
+				return err
+			}
+
+			if err := r.Rename(args[0], args[1]); err != nil {
+				return err
+			}
+
+			a, err := r.Get(args[1])
+			if err != nil {
+				return err
+			}
+
+			if a.Kubeconfig != "" {
+				path := r.KubeconfigPath(a.Name)
+				if err := os.Rename(a.Kubeconfig, path); err != nil {
+					return fmt.Errorf("unable to move %s: %w", a.Kubeconfig, err)
+				}
+				a.Kubeconfig = path
+				r.Put(a)
+			}
+
+			if a.RcFile != "" {
+				alias, err := renderAlias(a)
+				if err != nil {
+					return err
+				}
+				if err := shell.Rename(a.RcFile, args[0], a.Name, alias); err != nil {
+					return fmt.Errorf("unable to rename alias: %w", err)
+				}
+			}
+
+			if err := r.Save(); err != nil {
+				return err
+			}
+			fmt.Printf("Renamed %s to %s\n", args[0], args[1])
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&root.force, "force", false, "use the new name even if it shadows a command on PATH")
+
+	root.cmd = cmd
+	return root
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/eiladin/ekalias/shell"
+	"github.com/spf13/cobra"
+)
+
+type rmCmd struct {
+	cmd *cobra.Command
+}
+
+func newRmCmd() *rmCmd {
+	var root = &rmCmd{}
+	var cmd = &cobra.Command{
+		Use:     "rm <alias>",
+		Aliases: []string{"remove"},
+		Short:   "remove an alias from the registry and your shell rc file",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			r, err := loadRegistry()
+			if err != nil {
+				return err
+			}
+
+			a, err := r.Get(args[0])
+			if err != nil {
+				return err
+			}
+
+			if a.RcFile != "" {
+				if err := shell.Uninstall(a.RcFile, a.Name); err != nil {
+					return fmt.Errorf("unable to uninstall alias: %w", err)
+				}
+			}
+
+			if a.Kubeconfig != "" {
+				if err := os.Remove(a.Kubeconfig); err != nil && !os.IsNotExist(err) {
+					return fmt.Errorf("unable to remove %s: %w", a.Kubeconfig, err)
+				}
+			}
+
+			if err := r.Remove(a.Name); err != nil {
+				return err
+			}
+			if err := r.Save(); err != nil {
+				return err
+			}
+			fmt.Printf("Removed %s\n", a.Name)
+			return nil
+		},
+	}
+
+	root.cmd = cmd
+	return root
+}
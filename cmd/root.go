@@ -1,79 +1,306 @@
 package cmd
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"log"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/eiladin/ekalias/aws"
 	"github.com/eiladin/ekalias/console"
+	"github.com/eiladin/ekalias/kubeconfig"
 	"github.com/eiladin/ekalias/kubectl"
+	"github.com/eiladin/ekalias/registry"
+	"github.com/eiladin/ekalias/shell"
 	"github.com/logrusorgru/aurora/v3"
 	"github.com/spf13/cobra"
 )
 
-func Execute(version string, args []string) {
-	newRootCmd(version).Execute(args)
+// Execute runs ekalias with args and returns the exit code for the process.
+func Execute(version string, args []string) int {
+	return newRootCmd(version).Execute(args)
 }
 
+// aliasName holds the values available to --name-template.
+type aliasName struct {
+	Profile   string
+	Context   string
+	Namespace string
+	Region    string
+	Cluster   string
+}
+
+const defaultNameTemplate = "{{.Profile}}-{{.Cluster}}"
+
 type rootCmd struct {
-	cmd *cobra.Command
+	cmd              *cobra.Command
+	install          bool
+	isolated         bool
+	force            bool
+	skipAccountCheck bool
+	nameTemplate     string
+	shell            string
+	context          string
+	namespace        string
+	nonInteractive   bool
+	verbose          bool
+	timeout          time.Duration
+	aws              aws.Options
 }
 
-func (cmd *rootCmd) Execute(args []string) {
+// cancelGrace is how long Execute waits for a cancelled command to stop.
+// Commands run by the executor stop right away, prompts waiting for input
+// do not stop at all.
+const cancelGrace = time.Second
+
+func (cmd *rootCmd) Execute(args []string) int {
 	cmd.cmd.SetArgs(args)
 
-	_ = cmd.cmd.Execute()
+	ctx, cancel := console.NotifyContext(context.Background())
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.cmd.ExecuteContext(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		select {
+		case err = <-done:
+		case <-time.After(cancelGrace):
+			err = console.ErrCancelled
+		}
+	}
+	printError(os.Stderr, err)
+	return exitCode(err)
 }
 
 func newRootCmd(version string) *rootCmd {
 	var root = &rootCmd{}
 	var cmd = &cobra.Command{
-		Use:           "ekalias",
+		Use:           "ekalias [alias]",
 		Short:         "generate shell aliases for switching AWS profiles and kube contexts",
-		SilenceUsage:  false,
-		SilenceErrors: false,
+		SilenceUsage:  true,
+		SilenceErrors: true,
 		Version:       version,
 		Args: func(cmd *cobra.Command, args []string) error {
 			return validateArgs(args)
 		},
-		Run: func(cmd *cobra.Command, args []string) {
-			executor := console.New(os.Stdin, os.Stdout, os.Stderr)
-			k := kubectl.New(executor)
-			aws := aws.New(executor)
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sh := root.shell
+			if sh == "" {
+				sh = shell.Detect(os.Getenv("SHELL"))
+			}
+			renderer, err := shell.NewRenderer(sh)
+			if err != nil {
+				return err
+			}
 
-			_, err := k.FindCli()
+			var name string
+			namer, err := shell.NewNamer("alias", root.nameTemplate)
 			if err != nil {
-				log.Fatalf("Unable to find kubectl -> %s", err.Error())
+				return err
+			}
+
+			awsOpts := root.aws
+			awsOpts.NonInteractive = root.nonInteractive
+			awsOpts.Timeout = root.timeout
+
+			executor := newExecutor(cmd)
+			if len(args) == 1 {
+				name = args[0]
+				if err := checkAliasName(renderer, executor, name, root.force); err != nil {
+					return err
+				}
 			}
+			k := kubectl.New(executor).WithContext(cmd.Context())
+			a := aws.New(executor).WithOptions(awsOpts).WithContext(cmd.Context())
 
-			_, err = aws.FindCli()
+			_, err = k.FindCli()
 			if err != nil {
-				log.Fatalf("Unable to find aws cli -> %s", err.Error())
+				return err
 			}
 
-			awsProfile, err := aws.SelectProfile()
+			_, err = a.FindCli()
 			if err != nil {
-				log.Fatal(err)
+				fmt.Println(aurora.Yellow(fmt.Sprintf("Unable to find aws cli, creating profiles and kube contexts will not work -> %s", err.Error())))
 			}
-			fmt.Println("")
-			kubeContext, err := k.SelectContext()
+
+			// b goes back to the previous step, profile -> context -> namespace
+			var awsProfile, kubeContext, namespace string
+			err = runSteps(
+				func() (err error) {
+					awsProfile, err = a.SelectProfile()
+					if err != nil {
+						return err
+					}
+					fmt.Println("")
+
+					// everything from here on runs under the selected profile
+					awsOpts.Profile = awsProfile
+					k = k.WithOptions(kubectl.Options{
+						Context:          root.context,
+						Namespace:        root.namespace,
+						NonInteractive:   root.nonInteractive,
+						SkipAccountCheck: root.skipAccountCheck,
+						AWS:              awsOpts,
+					})
+					return nil
+				},
+				func() (err error) {
+					if kubeContext, err = k.SelectContext(); err == nil {
+						fmt.Println("")
+					}
+					return err
+				},
+				func() (err error) {
+					if namespace, err = k.SelectNamespace(kubeContext); err == nil {
+						fmt.Println("")
+					}
+					return err
+				},
+			)
 			if err != nil {
-				log.Fatal(err)
+				return err
 			}
-			fmt.Println("")
-			fmt.Println(aurora.Green(console.BuildAlias(args[0], awsProfile, kubeContext)))
+
+			entry := registry.Alias{
+				Profile:   awsProfile,
+				Context:   kubeContext,
+				Namespace: namespace,
+				Shell:     sh,
+				CreatedAt: time.Now(),
+			}
+			data := aliasName{Profile: awsProfile, Context: kubeContext, Namespace: namespace, Cluster: kubeContext}
+			if arn, ok := clusterARN(kubeContext); ok {
+				entry.Region = arn.Region
+				entry.ClusterARN = arn.String()
+				data.Region = arn.Region
+				data.Cluster = arn.Name
+			}
+
+			if name == "" {
+				if name, err = namer(data); err != nil {
+					return err
+				}
+				if err := checkAliasName(renderer, executor, name, root.force); err != nil {
+					return err
+				}
+			}
+			entry.Name = name
+
+			r, err := loadRegistry()
+			if err != nil {
+				return err
+			}
+			// the alias may replace one of the same name
+			prev, _ := r.Get(name)
+
+			if root.isolated {
+				if entry.Kubeconfig, err = isolate(r, name, kubeContext, namespace); err != nil {
+					return fmt.Errorf("unable to write kubeconfig: %w", err)
+				}
+			}
+
+			alias := renderer.Render(shell.Alias{Name: name, Profile: awsProfile, Context: kubeContext, Namespace: namespace, Kubeconfig: entry.Kubeconfig})
+			fmt.Println(aurora.Green(alias))
+
+			// an alias that was installed stays installed, with its new
+			// definition
+			if root.install || prev.RcFile != "" {
+				entry.RcFile, err = installAlias(sh, name, alias)
+				if err != nil {
+					return fmt.Errorf("unable to install alias: %w", err)
+				}
+				fmt.Printf("\nAlias written to %s, open a new shell or source it to use the alias\n", entry.RcFile)
+			}
+
+			var l leftovers
+			l.add(prev, entry)
+			if err := l.remove(); err != nil {
+				return fmt.Errorf("unable to replace alias: %w", err)
+			}
+			r.Put(entry)
+			if err := r.Save(); err != nil {
+				return fmt.Errorf("unable to save alias: %w", err)
+			}
+			return nil
 		},
 	}
 
+	cmd.AddCommand(
+		newListCmd().cmd,
+		newShowCmd().cmd,
+		newRmCmd().cmd,
+		newRenameCmd().cmd,
+		newTokenCmd().cmd,
+		newSSOCmd().cmd,
+		newGenerateAllCmd().cmd,
+		newCleanCmd().cmd,
+		newExecCmd().cmd,
+		newSubshellCmd().cmd,
+	)
+
+	cmd.PersistentFlags().DurationVar(&root.timeout, "timeout", 2*time.Minute, "how long each aws and kubectl command or API request may take, 0 for no limit")
+	cmd.PersistentFlags().BoolVar(&root.verbose, "verbose", false, "show the error output of aws and kubectl commands as they run")
+	cmd.Flags().BoolVar(&root.install, "install", false, "write the alias to your shell rc file")
+	cmd.Flags().BoolVar(&root.isolated, "isolated", false, "give the alias its own kubeconfig file instead of changing the current context shared by every terminal")
+	cmd.Flags().BoolVar(&root.skipAccountCheck, "skip-account-check", false, "allow a kube context of another AWS account than the profile")
+	cmd.Flags().BoolVar(&root.force, "force", false, "use the alias name even if it shadows a command on PATH")
+	cmd.Flags().StringVar(&root.nameTemplate, "name-template", defaultNameTemplate, "template for the alias name when none is given, with .Profile, .Context, .Namespace, .Region, .Cluster and the lower and upper functions")
+	cmd.Flags().StringVar(&root.aws.Profile, "profile", "", "AWS profile to use instead of prompting")
+	cmd.Flags().StringVar(&root.context, "context", "", "kube context to use instead of prompting")
+	cmd.Flags().StringVar(&root.namespace, "namespace", "", "namespace the alias switches to, instead of prompting")
+	cmd.Flags().StringVar(&root.aws.Region, "region", "", "AWS region to look for clusters in when creating a kube context")
+	cmd.Flags().StringVar(&root.aws.Cluster, "cluster", "", "EKS cluster to create a kube context for")
+	cmd.Flags().StringVar(&root.aws.KubeAlias, "kube-alias", "", "alias for the kube context created for --cluster")
+	cmd.Flags().StringVar(&root.aws.Backend, "backend", aws.BackendCLI, fmt.Sprintf("how to discover EKS clusters: %s (aws cli) or %s (EKS API)", aws.BackendCLI, aws.BackendAPI))
+	cmd.Flags().StringVar(&root.aws.Endpoint, "eks-endpoint", "", "EKS API endpoint to use with --backend api")
+	cmd.Flags().IntVar(&root.aws.Workers, "workers", 8, fmt.Sprintf("number of regions to query at once with --region %s", aws.AllRegions))
+	cmd.Flags().BoolVar(&root.nonInteractive, "non-interactive", false, "fail instead of prompting when a value is missing")
+	cmd.Flags().StringVar(&root.shell, "shell", "", fmt.Sprintf("shell to generate the alias for (%s), detected from $SHELL by default", strings.Join(shell.Shells(), ", ")))
+
 	root.cmd = cmd
 	return root
 }
 
+// runSteps runs steps in order. A step returning console.ErrBack runs the
+// step before it again, or itself when it is the first one.
+func runSteps(steps ...func() error) error {
+	for i := 0; i < len(steps); {
+		err := steps[i]()
+		switch {
+		case errors.Is(err, console.ErrBack):
+			fmt.Println("")
+			if i > 0 {
+				i--
+			}
+		case err != nil:
+			return err
+		default:
+			i++
+		}
+	}
+	return nil
+}
+
 func validateArgs(args []string) error {
-	if len(args) != 1 {
-		return errors.New("alias name required")
+	if len(args) > 1 {
+		return errors.New("only one alias name can be given")
 	}
 	return nil
 }
+
+func clusterARN(kubeContext string) (aws.ClusterARN, bool) {
+	if c, err := kubeconfig.Find(kubeContext); err == nil && c.IsEKS() {
+		if arn, ok := aws.ParseClusterARN(c.Cluster); ok {
+			return arn, true
+		}
+	}
+	return aws.ParseClusterARN(kubeContext)
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/logrusorgru/aurora/v3"
+	"github.com/spf13/cobra"
+)
+
+type showCmd struct {
+	cmd *cobra.Command
+}
+
+func newShowCmd() *showCmd {
+	var root = &showCmd{}
+	var cmd = &cobra.Command{
+		Use:   "show <alias>",
+		Short: "show the profile and context an alias points to",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			r, err := loadRegistry()
+			if err != nil {
+				return err
+			}
+
+			a, err := r.Get(args[0])
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Name:        %s\n", a.Name)
+			fmt.Printf("AWS Profile: %s\n", a.Profile)
+			fmt.Printf("Context:     %s\n", a.Context)
+			if a.Namespace != "" {
+				fmt.Printf("Namespace:   %s\n", a.Namespace)
+			}
+			if a.Kubeconfig != "" {
+				fmt.Printf("Kubeconfig:  %s\n", a.Kubeconfig)
+			}
+			if a.Region != "" {
+				fmt.Printf("Region:      %s\n", a.Region)
+			}
+			if a.ClusterARN != "" {
+				fmt.Printf("Cluster:     %s\n", a.ClusterARN)
+			}
+			if a.RcFile != "" {
+				fmt.Printf("Installed:   %s\n", a.RcFile)
+			}
+			fmt.Printf("Created:     %s\n", a.CreatedAt.Format(time.RFC1123))
+
+			alias, err := renderAlias(a)
+			if err != nil {
+				return err
+			}
+			fmt.Println("")
+			fmt.Println(aurora.Green(alias))
+			return nil
+		},
+	}
+
+	root.cmd = cmd
+	return root
+}
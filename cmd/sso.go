@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/eiladin/ekalias/aws"
+	"github.com/eiladin/ekalias/sso"
+	"github.com/logrusorgru/aurora/v3"
+	"github.com/spf13/cobra"
+)
+
+type ssoCmd struct {
+	cmd *cobra.Command
+}
+
+type ssoSyncCmd struct {
+	cmd      *cobra.Command
+	opts     aws.SyncOptions
+	endpoint string
+	dryRun   bool
+}
+
+func newSSOCmd() *ssoCmd {
+	var root = &ssoCmd{}
+	var cmd = &cobra.Command{
+		Use:   "sso",
+		Short: "manage AWS profiles for an SSO portal",
+		Args:  cobra.NoArgs,
+	}
+	cmd.AddCommand(newSSOSyncCmd().cmd)
+
+	root.cmd = cmd
+	return root
+}
+
+func newSSOSyncCmd() *ssoSyncCmd {
+	var root = &ssoSyncCmd{}
+	var cmd = &cobra.Command{
+		Use:   "sync",
+		Short: "write a profile for every account and role available through SSO to ~/.aws/config",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := aws.ValidateRegion(root.opts.SSORegion); err != nil {
+				return err
+			}
+			if root.opts.Region != "" {
+				if err := aws.ValidateRegion(root.opts.Region); err != nil {
+					return err
+				}
+			}
+
+			token, err := sso.CachedToken(sso.CacheDir(), root.opts.StartURL, time.Now())
+			if err != nil {
+				return err
+			}
+
+			client := sso.Client{Region: root.opts.SSORegion, AccessToken: token.AccessToken, Endpoint: root.endpoint, Timeout: commandTimeout(cmd)}.WithContext(cmd.Context())
+			profiles, err := aws.SSOProfiles(client, root.opts)
+			if err != nil {
+				return err
+			}
+
+			diff, err := aws.SyncConfig(aws.ConfigFile(), profiles, root.dryRun)
+			if err != nil {
+				return fmt.Errorf("unable to update %s: %w", aws.ConfigFile(), err)
+			}
+			if diff == "" {
+				fmt.Printf("%d profiles in %s are up to date\n", len(profiles), aws.ConfigFile())
+				return nil
+			}
+
+			printDiff(diff)
+			if root.dryRun {
+				fmt.Printf("\nDry run, %s was not changed\n", aws.ConfigFile())
+				return nil
+			}
+			fmt.Printf("\nWrote %d profiles to %s\n", len(profiles), aws.ConfigFile())
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&root.opts.StartURL, "start-url", "", "AWS SSO start url, for example https://my-company.awsapps.com/start")
+	cmd.Flags().StringVar(&root.opts.SSORegion, "sso-region", "", "region of the AWS SSO portal")
+	cmd.Flags().StringVar(&root.opts.Region, "region", "", "default region to set on every profile")
+	cmd.Flags().StringVar(&root.opts.NameTemplate, "name-template", aws.DefaultProfileTemplate, "profile name template, with .AccountID, .AccountName, .RoleName and the lower and upper functions")
+	cmd.Flags().IntVar(&root.opts.Workers, "workers", 8, "number of accounts to list roles for at once")
+	cmd.Flags().StringVar(&root.endpoint, "sso-endpoint", "", "AWS SSO portal endpoint to use instead of the regional one")
+	cmd.Flags().BoolVar(&root.dryRun, "dry-run", false, "show the changes to ~/.aws/config without writing them")
+	_ = cmd.MarkFlagRequired("start-url")
+	_ = cmd.MarkFlagRequired("sso-region")
+
+	root.cmd = cmd
+	return root
+}
+
+func printDiff(diff string) {
+	for _, line := range strings.Split(strings.TrimRight(diff, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "+"):
+			fmt.Println(aurora.Green(line))
+		case strings.HasPrefix(line, "-"):
+			fmt.Println(aurora.Red(line))
+		default:
+			fmt.Println(line)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/eiladin/ekalias/shell"
+	"github.com/logrusorgru/aurora/v3"
+	"github.com/spf13/cobra"
+)
+
+const activeEnv = "EKALIAS_ACTIVE"
+
+type subshellCmd struct {
+	cmd *cobra.Command
+}
+
+func newSubshellCmd() *subshellCmd {
+	var root = &subshellCmd{}
+	var cmd = &cobra.Command{
+		Use:   "shell <alias>",
+		Short: "start a shell using the profile and context of an alias, exit it to go back",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			r, err := loadRegistry()
+			if err != nil {
+				return err
+			}
+			a, err := r.Get(args[0])
+			if err != nil {
+				return err
+			}
+
+			sh := os.Getenv("SHELL")
+			if sh == "" {
+				sh = shell.Detect(sh)
+			}
+
+			if active := os.Getenv(activeEnv); active != "" {
+				fmt.Fprintln(os.Stderr, aurora.Yellow(fmt.Sprintf("Starting a nested shell inside the ekalias shell for %s", active)))
+			}
+			fmt.Fprintf(os.Stderr, "Starting %s for %s, exit it to go back\n", sh, a.Name)
+
+			err = execAlias(cmd.Context(), newExecutor(cmd), a, sh, nil, activeEnv+"="+a.Name)
+			fmt.Fprintf(os.Stderr, "Left the ekalias shell for %s\n", a.Name)
+			return err
+		},
+	}
+
+	root.cmd = cmd
+	return root
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/eiladin/ekalias/aws"
+	"github.com/eiladin/ekalias/eks"
+	"github.com/spf13/cobra"
+)
+
+type tokenCmd struct {
+	cmd     *cobra.Command
+	cluster string
+	region  string
+}
+
+type execCredential struct {
+	APIVersion string               `json:"apiVersion"`
+	Kind       string               `json:"kind"`
+	Spec       struct{}             `json:"spec"`
+	Status     execCredentialStatus `json:"status"`
+}
+
+type execCredentialStatus struct {
+	ExpirationTimestamp string `json:"expirationTimestamp"`
+	Token               string `json:"token"`
+}
+
+func newTokenCmd() *tokenCmd {
+	var root = &tokenCmd{}
+	var cmd = &cobra.Command{
+		Use:    "token",
+		Short:  "print an EKS authentication token for kubectl",
+		Hidden: true,
+		Args:   cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			creds, err := aws.ResolveCredentials(os.Getenv("AWS_PROFILE"))
+			if err != nil {
+				return err
+			}
+
+			token, expires, err := eks.Token(creds, root.region, root.cluster, time.Now())
+			if err != nil {
+				return err
+			}
+
+			cred := execCredential{
+				APIVersion: "client.authentication.k8s.io/v1beta1",
+				Kind:       "ExecCredential",
+				Status: execCredentialStatus{
+					ExpirationTimestamp: expires.UTC().Format(time.RFC3339),
+					Token:               token,
+				},
+			}
+			if err := json.NewEncoder(os.Stdout).Encode(cred); err != nil {
+				return err
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&root.cluster, "cluster-name", "", "name of the EKS cluster")
+	cmd.Flags().StringVar(&root.region, "region", "", "region of the EKS cluster")
+	_ = cmd.MarkFlagRequired("cluster-name")
+	_ = cmd.MarkFlagRequired("region")
+
+	root.cmd = cmd
+	return root
+}
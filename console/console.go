@@ -2,28 +2,68 @@ package console
 
 import (
 	"bufio"
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"os"
 	"os/exec"
+	"os/signal"
 	"strconv"
 	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
 
 	"github.com/logrusorgru/aurora/v3"
 )
 
+var ErrNonInteractive = errors.New("input required in non-interactive mode")
+
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent marks an error returned by the "Create New" func of
+// SelectValueFromList as one that retrying cannot fix, ending the selection.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return permanentError{err: err}
+}
+
+// Executor prompts the user and runs commands. Answering b to a prompt or a
+// list returns ErrBack, so the caller can go back to the previous step, and
+// answering q returns ErrCancelled.
 type Executor interface {
 	PromptInput(prompt string) (string, error)
 	ReadInput() (string, error)
-	ExecCommand(string, ...string) (string, error)
-	ExecInteractive(string, ...string) error
+	ExecCommand(context.Context, string, ...string) (string, error)
+	ExecInteractive(context.Context, string, ...string) error
 	FindExecutable(string) (string, error)
 	SelectValueFromList([]string, string, func() (string, error)) (string, error)
+	Warn(string)
+	// WithEnv returns an executor whose commands get env, as KEY=value
+	// pairs, on top of the current environment.
+	WithEnv(...string) Executor
 }
 
+// DefaultExecutor runs commands with the given standard streams. Env, when
+// set, replaces the environment of the commands it runs. Verbose also shows
+// the stderr of commands run by ExecCommand as it is written, and Timeout
+// limits how long each of them may take.
 type DefaultExecutor struct {
-	Stdin  io.Reader
-	Stdout io.Writer
-	Stderr io.Writer
+	Stdin   io.Reader
+	Stdout  io.Writer
+	Stderr  io.Writer
+	Env     []string
+	Verbose bool
+	Timeout time.Duration
 }
 
 var _ Executor = DefaultExecutor{}
@@ -33,6 +73,13 @@ func New(in io.Reader, out, err io.Writer) Executor {
 }
 
 func (e DefaultExecutor) SelectValueFromList(list []string, description string, newFunc func() (string, error)) (string, error) {
+	if isTerminal(e.Stdin) {
+		return e.fuzzySelect(list, description, newFunc)
+	}
+	return e.numberedSelect(list, description, newFunc)
+}
+
+func (e DefaultExecutor) numberedSelect(list []string, description string, newFunc func() (string, error)) (string, error) {
 	var result string
 	for len(result) == 0 {
 		count := 0
@@ -47,7 +94,7 @@ func (e DefaultExecutor) SelectValueFromList(list []string, description string,
 			fmt.Fprintf(e.Stdout, "%d. %s\n", count, "Create New")
 		}
 
-		r, err := e.PromptInput(fmt.Sprintf("\nSelect %s [%d-%d]: ", description, 1, count))
+		r, err := e.PromptInput(fmt.Sprintf("\nSelect %s [%d-%d, b: back, q: quit]: ", description, 1, count))
 		if err != nil {
 			return "", err
 		}
@@ -59,9 +106,11 @@ func (e DefaultExecutor) SelectValueFromList(list []string, description string,
 		case err != nil || i > count || i < 1:
 			fmt.Fprintln(e.Stdout, errInvalidInput)
 		case i == count && newFunc != nil:
-			for result, err = newFunc(); err != nil; result, err = newFunc() {
-				fmt.Fprintln(e.Stdout, aurora.Red(err))
+			created, err := e.create(newFunc)
+			if errors.Is(err, ErrBack) {
+				continue
 			}
+			return created, err
 		default:
 			result = list[i-1]
 		}
@@ -69,13 +118,42 @@ func (e DefaultExecutor) SelectValueFromList(list []string, description string,
 	return result, nil
 }
 
-func BuildAlias(aliasname, awsProfile, kubeContext string) string {
-	return fmt.Sprintf(`alias %s="export AWS_PROFILE=%s && kubectl config use-context %s"`, aliasname, awsProfile, kubeContext)
+// maxCreateAttempts is how many times the "Create New" func of
+// SelectValueFromList is tried before its error is returned.
+const maxCreateAttempts = 3
+
+// create calls newFunc until it succeeds or maxCreateAttempts is reached.
+// Permanent errors, running out of input, going back and cancelling end the
+// loop right away.
+func (e DefaultExecutor) create(newFunc func() (string, error)) (string, error) {
+	for attempt := 1; ; attempt++ {
+		result, err := newFunc()
+		var permanent permanentError
+		switch {
+		case err == nil:
+			return result, nil
+		case errors.As(err, &permanent), errors.Is(err, io.EOF), errors.Is(err, ErrCancelled), errors.Is(err, ErrBack), attempt == maxCreateAttempts:
+			return "", err
+		}
+		fmt.Fprintln(e.Stdout, aurora.Red(fmt.Sprintf("%s, %d attempts left", err, maxCreateAttempts-attempt)))
+	}
 }
 
+// PromptInput shows prompt and returns the line typed in reply, or ErrBack
+// and ErrCancelled for b and q.
 func (e DefaultExecutor) PromptInput(prompt string) (string, error) {
 	fmt.Fprint(e.Stdout, prompt)
-	return e.ReadInput()
+	r, err := e.ReadInput()
+	if err != nil {
+		return "", err
+	}
+	switch strings.TrimSpace(r) {
+	case "b":
+		return "", ErrBack
+	case "q":
+		return "", ErrCancelled
+	}
+	return r, nil
 }
 
 func (e DefaultExecutor) ReadInput() (string, error) {
@@ -87,34 +165,151 @@ func (e DefaultExecutor) ReadInput() (string, error) {
 	return strings.Replace(r, "\n", "", -1), nil
 }
 
-func (e DefaultExecutor) ExecCommand(name string, arg ...string) (string, error) {
+// ExecCommand runs a command and returns its output. The command's stderr
+// is kept out of the terminal, unless Verbose is set, and returned in a
+// *CommandError if it fails. It is killed when ctx is done or Timeout has
+// passed.
+//
+// When stdin is a terminal the command can read from it, and from the
+// terminal itself, to ask for an MFA code for example. Otherwise it gets no
+// input and runs in a process group of its own, so the processes it started
+// are killed with it.
+func (e DefaultExecutor) ExecCommand(ctx context.Context, name string, arg ...string) (string, error) {
+	if e.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.Timeout)
+		defer cancel()
+	}
+	if err := ctx.Err(); err != nil {
+		return "", commandError(ctx, err, name, arg, "")
+	}
+
 	cmd := &exec.Cmd{
-		Path:   name,
-		Args:   append([]string{name}, arg...),
-		Stdin:  e.Stdin,
-		Stderr: e.Stderr,
+		Path: name,
+		Args: append([]string{name}, arg...),
+		Env:  e.Env,
+	}
+	kill := func() error { return killProcessGroup(cmd) }
+	if isTerminal(e.Stdin) {
+		// the command stays in the terminal's foreground process group,
+		// where it may read the terminal and gets Ctrl-C itself
+		cmd.Stdin = e.Stdin
+		kill = func() error { return cmd.Process.Kill() }
+	} else {
+		setProcessGroup(cmd)
 	}
 
-	out, err := cmd.Output()
-	return string(out), err
+	// the pipes are read here rather than by cmd, so that Wait does not
+	// wait for processes a killed command left behind with them open
+	outPipe, err := cmd.StdoutPipe()
+	if err != nil {
+		return "", err
+	}
+	errPipe, err := cmd.StderrPipe()
+	if err != nil {
+		return "", err
+	}
+	var stdout, stderr bytes.Buffer
+	var errOut io.Writer = &stderr
+	if e.Verbose {
+		errOut = io.MultiWriter(&stderr, e.Stderr)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return "", err
+	}
+	copied := make(chan struct{})
+	go func() {
+		defer close(copied)
+		errCopied := make(chan struct{})
+		go func() {
+			defer close(errCopied)
+			_, _ = io.Copy(errOut, errPipe)
+		}()
+		_, _ = io.Copy(&stdout, outPipe)
+		<-errCopied
+	}()
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = kill()
+		case <-done:
+		}
+	}()
+
+	select {
+	case <-copied:
+	case <-ctx.Done():
+	}
+	err = cmd.Wait()
+	<-copied
+	close(done)
+	if err != nil {
+		return stdout.String(), commandError(ctx, err, name, arg, stderr.String())
+	}
+	return stdout.String(), nil
 }
 
-func (e DefaultExecutor) ExecInteractive(name string, arg ...string) error {
-	cmd := &exec.Cmd{
-		Path:   name,
-		Args:   append([]string{name}, arg...),
-		Stdin:  e.Stdin,
-		Stdout: e.Stdout,
-		Stderr: e.Stderr,
+// ExecInteractive runs a command attached to the executor's streams. Signals
+// sent to ekalias, such as Ctrl-C, are passed on to the command, which
+// decides whether to exit. The command is killed when ctx is done for any
+// other reason.
+func (e DefaultExecutor) ExecInteractive(ctx context.Context, name string, arg ...string) error {
+	if err := ctx.Err(); err != nil {
+		return commandError(ctx, err, name, arg, "")
 	}
 
-	return cmd.Run()
+	cmd := exec.CommandContext(ctx, name, arg...)
+	cmd.Env = e.Env
+	cmd.Stdin = e.Stdin
+	cmd.Stdout = e.Stdout
+	cmd.Stderr = e.Stderr
+
+	atomic.AddInt32(&interactive, 1)
+	defer atomic.AddInt32(&interactive, -1)
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case sig := <-signals:
+				_ = cmd.Process.Signal(sig)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return commandError(ctx, cmd.Wait(), name, arg, "")
+}
+
+// WithEnv leaves the environment of ekalias itself untouched, so values
+// such as AWS_PROFILE only reach the commands that are meant to see them.
+func (e DefaultExecutor) WithEnv(env ...string) Executor {
+	base := e.Env
+	if base == nil {
+		base = os.Environ()
+	}
+	e.Env = append(append([]string{}, base...), env...)
+	return e
+}
+
+func (e DefaultExecutor) Warn(msg string) {
+	fmt.Fprintln(e.Stderr, aurora.Yellow(msg))
 }
 
 func (e DefaultExecutor) FindExecutable(name string) (string, error) {
 	p, err := exec.LookPath(name)
 	if err != nil {
-		return "", err
+		return "", fmt.Errorf("%w: %s", ErrCliNotFound, name)
 	}
 	return p, nil
 }
@@ -2,10 +2,13 @@ package console
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"io"
+	"os"
 	"os/exec"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/suite"
 )
@@ -30,11 +33,6 @@ func (suite ConsoleSuite) TestNew() {
 	suite.Equal(&err, e.Stdin)
 }
 
-func (suite ConsoleSuite) TestBuildAlias() {
-	res := BuildAlias("aliasname", "profile", "context")
-	suite.Equal(`alias aliasname="export AWS_PROFILE=profile && kubectl config use-context context"`, res)
-}
-
 func (suite ConsoleSuite) TestReadInput() {
 	content := "test input"
 
@@ -59,15 +57,107 @@ func (suite ConsoleSuite) TestExecCommand() {
 	path, err := exec.LookPath("echo")
 	suite.NoError(err)
 
-	res, err := e.ExecCommand(path, "hello", "world")
+	res, err := e.ExecCommand(context.Background(), path, "hello", "world")
 	suite.NoError(err)
 	suite.Equal("hello world\n", res)
 
-	res, err = e.ExecCommand("echo1")
+	res, err = e.ExecCommand(context.Background(), "echo1")
 	suite.Error(err)
 	suite.Empty(res)
 }
 
+func (suite ConsoleSuite) TestExecCommandError() {
+	path, err := exec.LookPath("sh")
+	suite.Require().NoError(err)
+
+	var stderr bytes.Buffer
+	e := New(nil, &bytes.Buffer{}, &stderr)
+
+	_, err = e.ExecCommand(context.Background(), path, "-c", "echo 'An error occurred (AccessDenied)' >&2; exit 255", "--profile", "dev")
+	var cmdErr *CommandError
+	suite.Require().True(errors.As(err, &cmdErr))
+	suite.Equal(255, cmdErr.ExitCode)
+	suite.Equal("An error occurred (AccessDenied)\n", cmdErr.Stderr)
+	suite.Equal("sh failed with exit code 255: An error occurred (AccessDenied)", err.Error())
+	suite.Empty(stderr.String())
+
+	e = DefaultExecutor{Stdout: &bytes.Buffer{}, Stderr: &stderr, Verbose: true}
+	_, err = e.ExecCommand(context.Background(), path, "-c", "echo 'An error occurred (AccessDenied)' >&2; exit 255")
+	suite.Require().True(errors.As(err, &cmdErr))
+	suite.Equal("An error occurred (AccessDenied)\n", cmdErr.Stderr)
+	suite.Equal("An error occurred (AccessDenied)\n", stderr.String())
+
+	err = e.ExecInteractive(context.Background(), path, "-c", "kill -TERM $$")
+	suite.Require().True(errors.As(err, &cmdErr))
+	suite.Equal(128+15, cmdErr.ExitCode)
+	suite.Empty(cmdErr.Stderr)
+}
+
+func (suite ConsoleSuite) TestExecEnv() {
+	path, err := exec.LookPath("sh")
+	suite.Require().NoError(err)
+
+	var stdout bytes.Buffer
+	e := DefaultExecutor{Stdout: &stdout, Stderr: &bytes.Buffer{}, Env: []string{"AWS_PROFILE=prod"}}
+
+	res, err := e.ExecCommand(context.Background(), path, "-c", "echo $AWS_PROFILE")
+	suite.NoError(err)
+	suite.Equal("prod\n", res)
+
+	suite.NoError(e.ExecInteractive(context.Background(), path, "-c", "echo $AWS_PROFILE"))
+	suite.Equal("prod\n", stdout.String())
+
+	err = e.ExecInteractive(context.Background(), path, "-c", "exit 3")
+	var exitErr *exec.ExitError
+	suite.True(errors.As(err, &exitErr))
+	suite.Equal(3, exitErr.ExitCode())
+}
+
+func (suite ConsoleSuite) TestExecCommandContext() {
+	path, err := exec.LookPath("sh")
+	suite.Require().NoError(err)
+
+	e := DefaultExecutor{Stderr: &bytes.Buffer{}, Timeout: 50 * time.Millisecond}
+	start := time.Now()
+	_, err = e.ExecCommand(context.Background(), path, "-c", "sleep 10")
+	suite.True(errors.Is(err, ErrTimeout))
+	suite.Less(int64(time.Since(start)), int64(5*time.Second))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+	_, err = DefaultExecutor{Stderr: &bytes.Buffer{}}.ExecCommand(ctx, path, "-c", "sleep 10")
+	suite.True(errors.Is(err, ErrCancelled))
+	suite.Equal("cancelled: sh was stopped", err.Error())
+
+	err = DefaultExecutor{}.ExecInteractive(ctx, path, "-c", "exit 0")
+	suite.True(errors.Is(err, ErrCancelled))
+
+	res, err := e.ExecCommand(context.Background(), path, "-c", "echo done")
+	suite.NoError(err)
+	suite.Equal("done\n", res)
+}
+
+func (suite ConsoleSuite) TestWithEnv() {
+	path, err := exec.LookPath("sh")
+	suite.Require().NoError(err)
+
+	base := DefaultExecutor{Stderr: &bytes.Buffer{}}
+	e := base.WithEnv("EKALIAS_TEST_PROFILE=dev")
+	suite.Empty(os.Getenv("EKALIAS_TEST_PROFILE"))
+	suite.Nil(base.Env)
+
+	res, err := e.ExecCommand(context.Background(), path, "-c", "echo $EKALIAS_TEST_PROFILE $HOME")
+	suite.NoError(err)
+	suite.Equal("dev "+os.Getenv("HOME")+"\n", res)
+
+	res, err = e.WithEnv("EKALIAS_TEST_PROFILE=prod").ExecCommand(context.Background(), path, "-c", "echo $EKALIAS_TEST_PROFILE")
+	suite.NoError(err)
+	suite.Equal("prod\n", res)
+}
+
 func (suite ConsoleSuite) TestExecInteractive() {
 	var stdout bytes.Buffer
 	var stderr bytes.Buffer
@@ -76,11 +166,21 @@ func (suite ConsoleSuite) TestExecInteractive() {
 	path, err := exec.LookPath("echo")
 	suite.NoError(err)
 
-	err = e.ExecInteractive(path, "hello", "world")
+	err = e.ExecInteractive(context.Background(), path, "hello", "world")
 	suite.NoError(err)
 	suite.Equal("hello world\n", stdout.String())
 }
 
+func (suite ConsoleSuite) TestWarn() {
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	e := New(nil, &stdout, &stderr)
+
+	e.Warn("careful")
+	suite.Contains(stderr.String(), "careful")
+	suite.Empty(stdout.String())
+}
+
 func (suite ConsoleSuite) TestFindExecutable() {
 	var stdout bytes.Buffer
 	var stderr bytes.Buffer
@@ -91,7 +191,8 @@ func (suite ConsoleSuite) TestFindExecutable() {
 	suite.Contains(res, "echo")
 
 	res, err = e.FindExecutable("echo1")
-	suite.Error(err)
+	suite.True(errors.Is(err, ErrCliNotFound))
+	suite.Equal("command not found on PATH: echo1", err.Error())
 	suite.Empty(res)
 }
 
@@ -188,6 +289,75 @@ func (suite ConsoleSuite) TestSelectValueFromListInvalidSelection() {
 	}
 }
 
+func (suite ConsoleSuite) TestSelectValueFromListPermanentError() {
+	cases := []struct {
+		err   error
+		calls int
+	}{
+		{err: Permanent(errors.New("login required")), calls: 1},
+		{err: io.EOF, calls: 1},
+		{err: ErrCancelled, calls: 1},
+		{err: errors.New("access denied"), calls: maxCreateAttempts},
+	}
+
+	for _, c := range cases {
+		calls := 0
+		stdin := mockReader{list: []string{"2"}}
+		var stdout bytes.Buffer
+		e := New(&stdin, &stdout, &bytes.Buffer{})
+
+		res, err := e.SelectValueFromList([]string{"a"}, "test item", func() (string, error) {
+			calls++
+			return "", c.err
+		})
+		suite.True(errors.Is(err, c.err), err)
+		suite.Empty(res)
+		suite.Equal(c.calls, calls)
+	}
+
+	suite.Nil(Permanent(nil))
+	suite.EqualError(Permanent(errors.New("login required")), "login required")
+}
+
+func (suite ConsoleSuite) TestSelectValueFromListNavigation() {
+	cases := []struct {
+		input    []string
+		newErr   error
+		expected string
+		err      error
+	}{
+		{input: []string{"b"}, err: ErrBack},
+		{input: []string{"q"}, err: ErrCancelled},
+		{input: []string{" q "}, err: ErrCancelled},
+		{input: []string{"2", "b", "1"}, expected: "a"},
+		{input: []string{"2", "q"}, err: ErrCancelled},
+		{input: []string{"2"}, newErr: Permanent(ErrBack)},
+	}
+
+	for _, c := range cases {
+		stdin := mockReader{list: c.input}
+		e := New(&stdin, &bytes.Buffer{}, &bytes.Buffer{})
+
+		res, err := e.SelectValueFromList([]string{"a"}, "test item", func() (string, error) {
+			if c.newErr != nil {
+				return "", c.newErr
+			}
+			return e.PromptInput("Name: ")
+		})
+		if c.err != nil {
+			suite.True(errors.Is(err, c.err), c.input)
+			continue
+		}
+		if c.newErr != nil {
+			// going back shows the list again, which then runs out of input
+			suite.True(errors.Is(err, io.EOF), c.input)
+			continue
+		}
+		suite.NoError(err, c.input)
+		suite.Equal(c.expected, res)
+	}
+}
+
 type mockReader struct {
 	list []string
 }
@@ -0,0 +1,98 @@
+package console
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+var ErrCliNotFound = errors.New("command not found on PATH")
+var ErrCancelled = errors.New("cancelled")
+var ErrBack = errors.New("back to the previous step")
+var ErrTimeout = errors.New("timed out")
+
+// CommandError is returned when a command ran but did not succeed. ExitCode
+// is 128 plus the signal number when the command was killed by a signal,
+// and Stderr holds what the command wrote to stderr, when it was captured.
+type CommandError struct {
+	Name     string
+	Args     []string
+	ExitCode int
+	Stderr   string
+	Err      error
+}
+
+func (e *CommandError) Error() string {
+	msg := fmt.Sprintf("%s failed with exit code %d", e.Command(), e.ExitCode)
+	if stderr := strings.TrimSpace(e.Stderr); stderr != "" {
+		msg += ": " + stderr
+	}
+	return msg
+}
+
+func (e *CommandError) Unwrap() error { return e.Err }
+
+// Command returns the command and its first arguments, such as
+// "aws eks list-clusters", leaving out flags.
+func (e *CommandError) Command() string {
+	return commandLine(e.Name, e.Args)
+}
+
+func commandLine(name string, args []string) string {
+	words := []string{filepath.Base(name)}
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-") {
+			break
+		}
+		words = append(words, arg)
+	}
+	return strings.Join(words, " ")
+}
+
+// commandError turns the error of a finished command into a CommandError,
+// or into ErrCancelled or ErrTimeout when ctx ended the command. Errors from
+// starting the command are returned as they are.
+func commandError(ctx context.Context, err error, name string, args []string, stderr string) error {
+	if err == nil {
+		return nil
+	}
+	if err := Interrupted(ctx, commandLine(name, args)); err != nil {
+		return err
+	}
+
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return err
+	}
+	code := exitErr.ExitCode()
+	if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		code = 128 + int(status.Signal())
+	}
+	return &CommandError{Name: name, Args: args, ExitCode: code, Stderr: stderr, Err: err}
+}
+
+// Interrupted returns ErrCancelled or ErrTimeout, saying what was stopped,
+// when ctx has ended, and nil otherwise.
+func Interrupted(ctx context.Context, what string) error {
+	switch ctx.Err() {
+	case context.Canceled:
+		return fmt.Errorf("%w: %s was stopped", ErrCancelled, what)
+	case context.DeadlineExceeded:
+		return fmt.Errorf("%w: %s did not finish in time, use --timeout to allow more time", ErrTimeout, what)
+	}
+	return nil
+}
+
+// RequestError returns ErrCancelled or ErrTimeout for an HTTP request that
+// failed because ctx ended, and err otherwise.
+func RequestError(ctx context.Context, err error, req *http.Request) error {
+	if stopped := Interrupted(ctx, "request to "+req.URL.Host); stopped != nil {
+		return stopped
+	}
+	return err
+}
@@ -0,0 +1,376 @@
+package console
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/logrusorgru/aurora/v3"
+)
+
+const (
+	createNew   = "Create New"
+	pickerLines = 10
+)
+
+type key struct {
+	code int
+	r    rune
+}
+
+const (
+	keyRune = iota
+	keyUp
+	keyDown
+	keyEnter
+	keyBackspace
+	keyCancel
+	keyBack
+	keyIgnored
+)
+
+type match struct {
+	index     int
+	score     int
+	positions []int
+}
+
+type picker struct {
+	description string
+	items       []string
+	create      bool
+	query       []rune
+	matches     []match
+	cursor      int
+	drawn       int
+}
+
+func isTerminal(r io.Reader) bool {
+	f, ok := r.(*os.File)
+	return ok && isTTY(f)
+}
+
+// fuzzyMatch reports whether all runes of pattern appear in s in order,
+// ignoring case. Consecutive runes and runes at the start of a word score higher.
+func fuzzyMatch(pattern, s string) (int, []int, bool) {
+	p := []rune(strings.ToLower(pattern))
+	if len(p) == 0 {
+		return 0, nil, true
+	}
+
+	rs := []rune(s)
+	var positions []int
+	score := 0
+	j := 0
+	for i, r := range rs {
+		if j == len(p) {
+			break
+		}
+		if unicode.ToLower(r) != p[j] {
+			continue
+		}
+		score++
+		switch {
+		case len(positions) > 0 && positions[len(positions)-1] == i-1:
+			score += 5
+		case i == 0 || !unicode.IsLetter(rs[i-1]) && !unicode.IsDigit(rs[i-1]):
+			score += 3
+		}
+		positions = append(positions, i)
+		j++
+	}
+	if j < len(p) {
+		return 0, nil, false
+	}
+	return score - (positions[len(positions)-1] - positions[0]), positions, true
+}
+
+func newPicker(list []string, description string, create bool) *picker {
+	p := &picker{description: description, create: create}
+	for _, item := range list {
+		if item != "" {
+			p.items = append(p.items, item)
+		}
+	}
+	p.filter()
+	return p
+}
+
+func (p *picker) filter() {
+	p.matches = p.matches[:0]
+	for i, item := range p.items {
+		if score, positions, ok := fuzzyMatch(string(p.query), item); ok {
+			p.matches = append(p.matches, match{index: i, score: score, positions: positions})
+		}
+	}
+	sort.SliceStable(p.matches, func(i, j int) bool { return p.matches[i].score > p.matches[j].score })
+	p.cursor = 0
+}
+
+func (p *picker) rows() int {
+	if p.create {
+		return len(p.matches) + 1
+	}
+	return len(p.matches)
+}
+
+// handleKey applies k and reports whether the selection is complete.
+func (p *picker) handleKey(k key) bool {
+	switch k.code {
+	case keyUp:
+		if p.cursor > 0 {
+			p.cursor--
+		}
+	case keyDown:
+		if p.cursor < p.rows()-1 {
+			p.cursor++
+		}
+	case keyBackspace:
+		if len(p.query) > 0 {
+			p.query = p.query[:len(p.query)-1]
+			p.filter()
+		}
+	case keyRune:
+		p.query = append(p.query, k.r)
+		p.filter()
+	case keyEnter:
+		return p.rows() > 0
+	}
+	return false
+}
+
+// selected returns the highlighted item, or an empty string when the
+// "Create New" entry is highlighted.
+func (p *picker) selected() string {
+	if p.cursor < len(p.matches) {
+		return p.items[p.matches[p.cursor].index]
+	}
+	return ""
+}
+
+func (p *picker) render(w io.Writer) {
+	if p.drawn > 0 {
+		fmt.Fprintf(w, "\x1b[%dA", p.drawn)
+	}
+	fmt.Fprint(w, "\r\x1b[J")
+
+	fmt.Fprintf(w, "Select %s: %s\n", p.description, string(p.query))
+	lines := 1
+
+	start := 0
+	if p.cursor >= pickerLines {
+		start = p.cursor - pickerLines + 1
+	}
+	for row := start; row < p.rows() && row < start+pickerLines; row++ {
+		prefix := "  "
+		if row == p.cursor {
+			prefix = aurora.Green("> ").String()
+		}
+		if row < len(p.matches) {
+			m := p.matches[row]
+			fmt.Fprintf(w, "%s%s\n", prefix, highlight(p.items[m.index], m.positions))
+		} else {
+			fmt.Fprintf(w, "%s%s\n", prefix, aurora.Faint(createNew))
+		}
+		lines++
+	}
+	if p.rows() == 0 {
+		fmt.Fprintln(w, aurora.Red("  no matches"))
+		lines++
+	}
+	fmt.Fprintln(w, aurora.Faint("  ← or ctrl-b back, esc quit"))
+	lines++
+	p.drawn = lines
+}
+
+func highlight(s string, positions []int) string {
+	var b strings.Builder
+	next := 0
+	for i, r := range []rune(s) {
+		if next < len(positions) && positions[next] == i {
+			b.WriteString(aurora.Bold(aurora.Cyan(string(r))).String())
+			next++
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// keyReader reads keys from a terminal in raw mode. The bytes of an escape
+// sequence arrive in a single read, so an escape byte read on its own is the
+// Esc key and is returned without waiting for more input.
+type keyReader struct {
+	r       io.Reader
+	pending []byte
+}
+
+func (kr *keyReader) readByte() (byte, error) {
+	if len(kr.pending) == 0 {
+		buf := make([]byte, 64)
+		for {
+			n, err := kr.r.Read(buf)
+			if n > 0 {
+				kr.pending = buf[:n]
+				break
+			}
+			if err != nil {
+				return 0, err
+			}
+		}
+	}
+	b := kr.pending[0]
+	kr.pending = kr.pending[1:]
+	return b, nil
+}
+
+func (kr *keyReader) readKey() (key, error) {
+	b, err := kr.readByte()
+	if err != nil {
+		return key{}, err
+	}
+
+	switch b {
+	case '\r', '\n':
+		return key{code: keyEnter}, nil
+	case 127, 8:
+		return key{code: keyBackspace}, nil
+	case 3, 4:
+		return key{code: keyCancel}, nil
+	case 2:
+		return key{code: keyBack}, nil
+	case 16:
+		return key{code: keyUp}, nil
+	case 14:
+		return key{code: keyDown}, nil
+	case 27:
+		if len(kr.pending) == 0 {
+			return key{code: keyCancel}, nil
+		}
+		return kr.readEscape()
+	}
+
+	if b < 0x20 {
+		return key{code: keyIgnored}, nil
+	}
+	if b < 0x80 {
+		return key{code: keyRune, r: rune(b)}, nil
+	}
+	return kr.readUTF8(b)
+}
+
+func (kr *keyReader) readEscape() (key, error) {
+	b, err := kr.readByte()
+	if err != nil {
+		return key{}, err
+	}
+	if b != '[' && b != 'O' {
+		return key{code: keyIgnored}, nil
+	}
+	b, err = kr.readByte()
+	if err != nil {
+		return key{}, err
+	}
+	switch b {
+	case 'A':
+		return key{code: keyUp}, nil
+	case 'B':
+		return key{code: keyDown}, nil
+	case 'D':
+		return key{code: keyBack}, nil
+	}
+	return key{code: keyIgnored}, nil
+}
+
+func (kr *keyReader) readUTF8(first byte) (key, error) {
+	buf := []byte{first}
+	for len(buf) < utf8.UTFMax && !utf8.FullRune(buf) {
+		b, err := kr.readByte()
+		if err != nil {
+			return key{}, err
+		}
+		buf = append(buf, b)
+	}
+	return key{code: keyRune, r: []rune(string(buf))[0]}, nil
+}
+
+// runPicker reads keys from in until an item is chosen, redrawing the list on
+// out. Every letter filters the list, so going back and quitting use ←,
+// ctrl-b and esc rather than the b and q of the numbered list.
+func runPicker(p *picker, in io.Reader, out io.Writer) (string, error) {
+	kr := &keyReader{r: in}
+	for {
+		p.render(out)
+		k, err := kr.readKey()
+		if err != nil {
+			return "", err
+		}
+		switch k.code {
+		case keyCancel:
+			return "", ErrCancelled
+		case keyBack:
+			return "", ErrBack
+		}
+		if p.handleKey(k) {
+			return p.selected(), nil
+		}
+	}
+}
+
+func (e DefaultExecutor) fuzzySelect(list []string, description string, newFunc func() (string, error)) (string, error) {
+	tty := e.Stdin.(*os.File)
+	restore, err := rawMode(tty)
+	if err != nil {
+		return e.numberedSelect(list, description, newFunc)
+	}
+
+	defer restore()
+
+	for {
+		p := newPicker(list, description, newFunc != nil)
+		result, err := runPicker(p, tty, e.Stdout)
+		if err != nil {
+			return "", err
+		}
+		if result != "" || newFunc == nil {
+			return result, nil
+		}
+
+		restore()
+		created, err := e.create(newFunc)
+		if !errors.Is(err, ErrBack) {
+			return created, err
+		}
+		if _, err := rawMode(tty); err != nil {
+			return "", err
+		}
+	}
+}
+
+// rawMode switches the terminal to unbuffered input without echo and
+// returns a func restoring the previous settings.
+func rawMode(tty *os.File) (func(), error) {
+	state, err := stty(tty, "-g")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := stty(tty, "-icanon", "-echo", "-isig", "min", "1", "time", "0"); err != nil {
+		return nil, err
+	}
+	return func() {
+		_, _ = stty(tty, strings.TrimSpace(state))
+	}, nil
+}
+
+func stty(tty *os.File, args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = tty
+	out, err := cmd.Output()
+	return string(out), err
+}
@@ -0,0 +1,202 @@
+package console
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type PickerSuite struct {
+	suite.Suite
+}
+
+func TestPickerSuite(t *testing.T) {
+	suite.Run(t, new(PickerSuite))
+}
+
+func (suite PickerSuite) TestIsTerminal() {
+	suite.False(isTerminal(&bytes.Buffer{}))
+	suite.False(isTerminal(nil))
+
+	f, err := ioutil.TempFile("", "ekalias")
+	suite.Require().NoError(err)
+	defer os.Remove(f.Name())
+	defer f.Close()
+	suite.False(isTerminal(f))
+
+	null, err := os.Open(os.DevNull)
+	suite.Require().NoError(err)
+	defer null.Close()
+	suite.False(isTerminal(null))
+}
+
+func (suite PickerSuite) TestFuzzyMatch() {
+	cases := []struct {
+		pattern   string
+		s         string
+		ok        bool
+		positions []int
+	}{
+		{pattern: "", s: "anything", ok: true},
+		{pattern: "pe", s: "prod-east", ok: true, positions: []int{0, 5}},
+		{pattern: "PROD", s: "prod-east", ok: true, positions: []int{0, 1, 2, 3}},
+		{pattern: "dp", s: "prod-east", ok: false},
+		{pattern: "xyz", s: "prod-east", ok: false},
+	}
+
+	for _, c := range cases {
+		_, positions, ok := fuzzyMatch(c.pattern, c.s)
+		suite.Equal(c.ok, ok, c.pattern)
+		suite.Equal(c.positions, positions, c.pattern)
+	}
+
+	consecutive, _, _ := fuzzyMatch("prod", "prod-east")
+	scattered, _, _ := fuzzyMatch("prod", "p-r-o-d")
+	suite.Greater(consecutive, scattered)
+}
+
+func (suite PickerSuite) TestFilter() {
+	p := newPicker([]string{"dev-west", "", "prod-east", "prod-west"}, "Profile", true)
+	suite.Equal([]string{"dev-west", "prod-east", "prod-west"}, p.items)
+	suite.Equal(4, p.rows())
+
+	p.handleKey(key{code: keyRune, r: 'w'})
+	p.handleKey(key{code: keyRune, r: 'e'})
+	suite.Len(p.matches, 2)
+	suite.Equal("dev-west", p.selected())
+
+	p.handleKey(key{code: keyRune, r: 'z'})
+	suite.Len(p.matches, 0)
+	suite.Equal(1, p.rows())
+	suite.Equal("", p.selected())
+
+	p.handleKey(key{code: keyBackspace})
+	suite.Len(p.matches, 2)
+}
+
+func (suite PickerSuite) TestNavigation() {
+	p := newPicker([]string{"a", "b"}, "Profile", true)
+	p.handleKey(key{code: keyUp})
+	suite.Equal("a", p.selected())
+	p.handleKey(key{code: keyDown})
+	suite.Equal("b", p.selected())
+	p.handleKey(key{code: keyDown})
+	suite.Equal("", p.selected())
+	p.handleKey(key{code: keyDown})
+	suite.Equal("", p.selected())
+
+	p = newPicker([]string{"a"}, "Cluster", false)
+	p.handleKey(key{code: keyRune, r: 'x'})
+	suite.False(p.handleKey(key{code: keyEnter}))
+}
+
+func (suite PickerSuite) TestRender() {
+	p := newPicker([]string{"prod-east", "dev-west"}, "AWS Profile", true)
+	p.handleKey(key{code: keyRune, r: 'p'})
+
+	var out bytes.Buffer
+	p.render(&out)
+	suite.Contains(out.String(), "Select AWS Profile: p\n")
+	suite.Contains(out.String(), "Create New")
+	suite.NotContains(out.String(), "dev-west")
+	suite.Contains(out.String(), "← or ctrl-b back, esc quit")
+	suite.Equal(4, p.drawn)
+
+	out.Reset()
+	p.render(&out)
+	suite.True(strings.HasPrefix(out.String(), "\x1b[4A"))
+}
+
+func (suite PickerSuite) TestReadKey() {
+	cases := []struct {
+		input    string
+		expected key
+	}{
+		{input: "a", expected: key{code: keyRune, r: 'a'}},
+		{input: "é", expected: key{code: keyRune, r: 'é'}},
+		{input: "\r", expected: key{code: keyEnter}},
+		{input: "\x7f", expected: key{code: keyBackspace}},
+		{input: "\x03", expected: key{code: keyCancel}},
+		{input: "\x02", expected: key{code: keyBack}},
+		{input: "\x1b[D", expected: key{code: keyBack}},
+		{input: "\x1b[A", expected: key{code: keyUp}},
+		{input: "\x1b[B", expected: key{code: keyDown}},
+		{input: "\x1b[C", expected: key{code: keyIgnored}},
+		{input: "\x01", expected: key{code: keyIgnored}},
+		{input: "\x1b", expected: key{code: keyCancel}},
+	}
+
+	for _, c := range cases {
+		k, err := (&keyReader{r: strings.NewReader(c.input)}).readKey()
+		suite.NoError(err)
+		suite.Equal(c.expected, k, c.input)
+	}
+
+	_, err := (&keyReader{r: strings.NewReader("")}).readKey()
+	suite.Error(err)
+}
+
+func (suite PickerSuite) TestRunPicker() {
+	var out bytes.Buffer
+
+	res, err := runPicker(newPicker([]string{"prod-east", "prod-west"}, "Profile", true), strings.NewReader("pw\r"), &out)
+	suite.NoError(err)
+	suite.Equal("prod-west", res)
+
+	res, err = runPicker(newPicker([]string{"prod-east", "prod-west"}, "Profile", true), strings.NewReader("\x1b[B\x1b[B\r"), &out)
+	suite.NoError(err)
+	suite.Equal("", res)
+
+	_, err = runPicker(newPicker([]string{"prod-east"}, "Profile", true), strings.NewReader("p\x03"), &out)
+	suite.Equal(ErrCancelled, err)
+
+	_, err = runPicker(newPicker([]string{"prod-east"}, "Profile", true), strings.NewReader("b\x1b[D"), &out)
+	suite.Equal(ErrBack, err)
+
+	_, err = runPicker(newPicker([]string{"prod-east"}, "Profile", true), strings.NewReader("p"), &out)
+	suite.Error(err)
+}
+
+// chunks returns one chunk per Read, as a terminal returns one key press.
+type chunks []string
+
+func (c *chunks) Read(b []byte) (int, error) {
+	if len(*c) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(b, (*c)[0])
+	*c = (*c)[1:]
+	return n, nil
+}
+
+func (suite PickerSuite) TestRunPickerKeys() {
+	cases := []struct {
+		input         chunks
+		expected      string
+		expectedError error
+	}{
+		{input: chunks{"b", "\r"}, expected: "web"},
+		{input: chunks{"q", "\r"}, expected: "dev-qa"},
+		{input: chunks{"\x1b"}, expectedError: ErrCancelled},
+		{input: chunks{"\x1b", "p", "\r"}, expectedError: ErrCancelled},
+		{input: chunks{"p", "\x1b"}, expectedError: ErrCancelled},
+		{input: chunks{"\x1b[D"}, expectedError: ErrBack},
+		{input: chunks{"\x02"}, expectedError: ErrBack},
+		{input: chunks{"w", "b", "\r"}, expected: "web"},
+		{input: chunks{"d", "q", "\r"}, expected: "dev-qa"},
+		{input: chunks{"p", "\x7f", "\x1b"}, expectedError: ErrCancelled},
+	}
+
+	for _, c := range cases {
+		var out bytes.Buffer
+		input := c.input
+		res, err := runPicker(newPicker([]string{"prod", "web", "dev-qa"}, "Profile", false), &input, &out)
+		suite.Equal(c.expectedError, err, c.input)
+		suite.Equal(c.expected, res, c.input)
+	}
+}
@@ -0,0 +1,12 @@
+//go:build windows
+// +build windows
+
+package console
+
+import "os/exec"
+
+func setProcessGroup(cmd *exec.Cmd) {}
+
+func killProcessGroup(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
@@ -0,0 +1,38 @@
+package console
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+)
+
+// interactive counts the commands ExecInteractive is running. They get the
+// signals sent to ekalias instead of NotifyContext.
+var interactive int32
+
+// NotifyContext returns a copy of ctx that is cancelled when ekalias gets
+// SIGINT or SIGTERM, which stops the commands run with it. While a command
+// run by ExecInteractive is in the foreground the signals are left to it.
+func NotifyContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		defer signal.Stop(signals)
+		for {
+			select {
+			case <-signals:
+				if atomic.LoadInt32(&interactive) == 0 {
+					cancel()
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ctx, cancel
+}
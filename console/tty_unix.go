@@ -0,0 +1,18 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+// +build darwin dragonfly freebsd linux netbsd openbsd
+
+package console
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// isTTY reports whether f is a terminal, by asking for its terminal
+// settings. Other character devices, such as /dev/null, have none.
+func isTTY(f *os.File) bool {
+	var termios syscall.Termios
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), ioctlGetTermios, uintptr(unsafe.Pointer(&termios)))
+	return errno == 0
+}
@@ -0,0 +1,15 @@
+//go:build windows
+// +build windows
+
+package console
+
+import (
+	"os"
+	"syscall"
+)
+
+// isTTY reports whether f is a console. NUL and pipes have no console mode.
+func isTTY(f *os.File) bool {
+	var mode uint32
+	return syscall.GetConsoleMode(syscall.Handle(f.Fd()), &mode) == nil
+}
@@ -0,0 +1,171 @@
+package eks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/eiladin/ekalias/console"
+)
+
+type Cluster struct {
+	Name                 string `json:"name"`
+	Arn                  string `json:"arn"`
+	Endpoint             string `json:"endpoint"`
+	CertificateAuthority struct {
+		Data string `json:"data"`
+	} `json:"certificateAuthority"`
+}
+
+type APIError struct {
+	StatusCode int
+	Type       string
+	Message    string
+}
+
+func (e APIError) Error() string {
+	if e.Type == "" {
+		return fmt.Sprintf("eks: %d %s", e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("eks: %s: %s", e.Type, e.Message)
+}
+
+// Client calls the EKS API directly. Endpoint overrides the regional
+// https://eks.<region>.amazonaws.com endpoint, which is useful for tests.
+// Timeout limits how long each request may take.
+type Client struct {
+	Region      string
+	Credentials Credentials
+	Endpoint    string
+	HTTPClient  *http.Client
+	Now         func() time.Time
+	Timeout     time.Duration
+	ctx         context.Context
+}
+
+// WithContext returns a client whose requests are stopped when ctx is done.
+func (c Client) WithContext(ctx context.Context) Client {
+	c.ctx = ctx
+	return c
+}
+
+func DNSSuffix(region string) string {
+	if strings.HasPrefix(region, "cn-") {
+		return "amazonaws.com.cn"
+	}
+	return "amazonaws.com"
+}
+
+func (c Client) endpoint() string {
+	if c.Endpoint != "" {
+		return strings.TrimSuffix(c.Endpoint, "/")
+	}
+	return fmt.Sprintf("https://eks.%s.%s", c.Region, DNSSuffix(c.Region))
+}
+
+func (c Client) ListClusters() ([]string, error) {
+	var clusters []string
+	var next string
+	for {
+		q := url.Values{}
+		q.Set("maxResults", "100")
+		if next != "" {
+			q.Set("nextToken", next)
+		}
+
+		var res struct {
+			Clusters  []string `json:"clusters"`
+			NextToken string   `json:"nextToken"`
+		}
+		if err := c.get("/clusters", q, &res); err != nil {
+			return nil, err
+		}
+		clusters = append(clusters, res.Clusters...)
+
+		if res.NextToken == "" {
+			return clusters, nil
+		}
+		next = res.NextToken
+	}
+}
+
+func (c Client) DescribeCluster(name string) (Cluster, error) {
+	var res struct {
+		Cluster Cluster `json:"cluster"`
+	}
+	err := c.get("/clusters/"+url.PathEscape(name), nil, &res)
+	return res.Cluster, err
+}
+
+func (c Client) get(path string, q url.Values, v interface{}) error {
+	u := c.endpoint() + path
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+	body, err := c.do(u, "eks")
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
+
+// do signs and sends a GET request for u, returning the body of a
+// successful response.
+func (c Client) do(u, service string) ([]byte, error) {
+	ctx := c.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now
+	if c.Now != nil {
+		now = c.Now
+	}
+	Sign(req, nil, c.Credentials, c.Region, service, now())
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, console.RequestError(ctx, err, req)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, console.RequestError(ctx, err, req)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		apiErr := APIError{StatusCode: resp.StatusCode, Type: resp.Header.Get("X-Amzn-Errortype")}
+		var msg struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(body, &msg) == nil {
+			apiErr.Message = msg.Message
+		} else {
+			apiErr.Message = strings.TrimSpace(string(body))
+		}
+		if i := strings.Index(apiErr.Type, ":"); i >= 0 {
+			apiErr.Type = apiErr.Type[:i]
+		}
+		return nil, apiErr
+	}
+	return body, nil
+}
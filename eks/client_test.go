@@ -0,0 +1,140 @@
+package eks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eiladin/ekalias/console"
+	"github.com/stretchr/testify/suite"
+)
+
+type ClientSuite struct {
+	suite.Suite
+}
+
+func TestClientSuite(t *testing.T) {
+	suite.Run(t, new(ClientSuite))
+}
+
+func stubServer(handler http.HandlerFunc) (*httptest.Server, Client) {
+	srv := httptest.NewServer(handler)
+	return srv, Client{
+		Region:      "us-east-1",
+		Credentials: testCreds,
+		Endpoint:    srv.URL,
+		Now:         func() time.Time { return testTime },
+	}
+}
+
+func (suite ClientSuite) TestEndpoint() {
+	suite.Equal("https://eks.us-east-1.amazonaws.com", Client{Region: "us-east-1"}.endpoint())
+	suite.Equal("https://eks.cn-north-1.amazonaws.com.cn", Client{Region: "cn-north-1"}.endpoint())
+	suite.Equal("http://localhost:8080", Client{Region: "us-east-1", Endpoint: "http://localhost:8080/"}.endpoint())
+}
+
+func (suite ClientSuite) TestListClusters() {
+	srv, c := stubServer(func(w http.ResponseWriter, r *http.Request) {
+		suite.Equal("/clusters", r.URL.Path)
+		suite.True(strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/eks/aws4_request"))
+		if r.URL.Query().Get("nextToken") == "" {
+			json.NewEncoder(w).Encode(map[string]interface{}{"clusters": []string{"a", "b"}, "nextToken": "page2"})
+			return
+		}
+		suite.Equal("page2", r.URL.Query().Get("nextToken"))
+		json.NewEncoder(w).Encode(map[string]interface{}{"clusters": []string{"c"}})
+	})
+	defer srv.Close()
+
+	res, err := c.ListClusters()
+	suite.NoError(err)
+	suite.Equal([]string{"a", "b", "c"}, res)
+}
+
+func (suite ClientSuite) TestDescribeCluster() {
+	srv, c := stubServer(func(w http.ResponseWriter, r *http.Request) {
+		suite.Equal("/clusters/prod", r.URL.Path)
+		w.Write([]byte(`{"cluster":{"name":"prod","arn":"arn:aws:eks:us-east-1:123456789012:cluster/prod","endpoint":"https://ABC.gr7.us-east-1.eks.amazonaws.com","certificateAuthority":{"data":"Y2E="}}}`))
+	})
+	defer srv.Close()
+
+	res, err := c.DescribeCluster("prod")
+	suite.NoError(err)
+	suite.Equal("arn:aws:eks:us-east-1:123456789012:cluster/prod", res.Arn)
+	suite.Equal("https://ABC.gr7.us-east-1.eks.amazonaws.com", res.Endpoint)
+	suite.Equal("Y2E=", res.CertificateAuthority.Data)
+}
+
+func (suite ClientSuite) TestAPIError() {
+	srv, c := stubServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Amzn-ErrorType", "ResourceNotFoundException:http://internal.amazon.com/")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message":"No cluster found for name: prod."}`))
+	})
+	defer srv.Close()
+
+	_, err := c.DescribeCluster("prod")
+	var apiErr APIError
+	suite.True(errors.As(err, &apiErr))
+	suite.Equal(http.StatusNotFound, apiErr.StatusCode)
+	suite.Equal("ResourceNotFoundException", apiErr.Type)
+	suite.Equal("eks: ResourceNotFoundException: No cluster found for name: prod.", err.Error())
+}
+
+func (suite ClientSuite) TestRegions() {
+	srv, c := stubServer(func(w http.ResponseWriter, r *http.Request) {
+		suite.Equal("DescribeRegions", r.URL.Query().Get("Action"))
+		suite.Contains(r.Header.Get("Authorization"), "/us-east-1/ec2/aws4_request")
+		w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<DescribeRegionsResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/">
+  <requestId>1</requestId>
+  <regionInfo>
+    <item><regionName>eu-north-1</regionName><regionEndpoint>ec2.eu-north-1.amazonaws.com</regionEndpoint></item>
+    <item><regionName>us-east-1</regionName><regionEndpoint>ec2.us-east-1.amazonaws.com</regionEndpoint></item>
+  </regionInfo>
+</DescribeRegionsResponse>`))
+	})
+	defer srv.Close()
+
+	res, err := c.Regions()
+	suite.NoError(err)
+	suite.Equal([]string{"eu-north-1", "us-east-1"}, res)
+
+	suite.Equal("https://ec2.cn-north-1.amazonaws.com.cn", Client{Region: "cn-north-1"}.ec2Endpoint())
+}
+
+func (suite ClientSuite) TestRegionsError() {
+	srv, c := stubServer(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`<Response><Errors><Error><Code>UnauthorizedOperation</Code></Error></Errors></Response>`))
+	})
+	defer srv.Close()
+
+	_, err := c.Regions()
+	var apiErr APIError
+	suite.True(errors.As(err, &apiErr))
+	suite.Equal(http.StatusForbidden, apiErr.StatusCode)
+}
+
+func (suite ClientSuite) TestStopped() {
+	srv, c := stubServer(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	})
+	defer srv.Close()
+
+	c.Timeout = 50 * time.Millisecond
+	_, err := c.ListClusters()
+	suite.True(errors.Is(err, console.ErrTimeout), err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	c.Timeout = 0
+	c = c.WithContext(ctx)
+	_, err = c.ListClusters()
+	suite.True(errors.Is(err, console.ErrCancelled), err)
+}
@@ -0,0 +1,43 @@
+package eks
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+type describeRegionsResponse struct {
+	Regions []struct {
+		RegionName string `xml:"regionName"`
+	} `xml:"regionInfo>item"`
+}
+
+func (c Client) ec2Endpoint() string {
+	if c.Endpoint != "" {
+		return strings.TrimSuffix(c.Endpoint, "/")
+	}
+	return fmt.Sprintf("https://ec2.%s.%s", c.Region, DNSSuffix(c.Region))
+}
+
+// Regions returns the regions enabled for the account, using ec2:DescribeRegions.
+func (c Client) Regions() ([]string, error) {
+	q := url.Values{}
+	q.Set("Action", "DescribeRegions")
+	q.Set("Version", "2016-11-15")
+
+	body, err := c.do(c.ec2Endpoint()+"/?"+q.Encode(), "ec2")
+	if err != nil {
+		return nil, err
+	}
+
+	var res describeRegionsResponse
+	if err := xml.Unmarshal(body, &res); err != nil {
+		return nil, err
+	}
+	regions := make([]string, len(res.Regions))
+	for i, r := range res.Regions {
+		regions[i] = r.RegionName
+	}
+	return regions, nil
+}
@@ -0,0 +1,154 @@
+package eks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	algorithm  = "AWS4-HMAC-SHA256"
+	timeFormat = "20060102T150405Z"
+	dateFormat = "20060102"
+)
+
+type Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// Sign adds a Signature Version 4 Authorization header to req.
+func Sign(req *http.Request, body []byte, creds Credentials, region, service string, t time.Time) {
+	t = t.UTC()
+	req.Header.Set("X-Amz-Date", t.Format(timeFormat))
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+
+	headers := []string{"host"}
+	for name := range req.Header {
+		lower := strings.ToLower(name)
+		if lower == "x-amz-date" || lower == "x-amz-security-token" || lower == "content-type" {
+			headers = append(headers, lower)
+		}
+	}
+	sort.Strings(headers)
+
+	scope := credentialScope(t, region, service)
+	signature := signature(creds, t, region, service, canonicalRequest(req, headers, hashHex(body)))
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		algorithm, creds.AccessKeyID, scope, strings.Join(headers, ";"), signature))
+}
+
+// Presign adds a Signature Version 4 signature to the query string of req,
+// signing the host header and every header already set on req.
+func Presign(req *http.Request, creds Credentials, region, service string, expires time.Duration, t time.Time) {
+	t = t.UTC()
+	headers := []string{"host"}
+	for name := range req.Header {
+		headers = append(headers, strings.ToLower(name))
+	}
+	sort.Strings(headers)
+
+	q := req.URL.Query()
+	q.Set("X-Amz-Algorithm", algorithm)
+	q.Set("X-Amz-Credential", creds.AccessKeyID+"/"+credentialScope(t, region, service))
+	q.Set("X-Amz-Date", t.Format(timeFormat))
+	q.Set("X-Amz-Expires", strconv.Itoa(int(expires.Seconds())))
+	q.Set("X-Amz-SignedHeaders", strings.Join(headers, ";"))
+	if creds.SessionToken != "" {
+		q.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+	req.URL.RawQuery = canonicalQuery(q)
+
+	sig := signature(creds, t, region, service, canonicalRequest(req, headers, hashHex(nil)))
+	req.URL.RawQuery += "&X-Amz-Signature=" + sig
+}
+
+func credentialScope(t time.Time, region, service string) string {
+	return strings.Join([]string{t.Format(dateFormat), region, service, "aws4_request"}, "/")
+}
+
+func canonicalRequest(req *http.Request, headers []string, payloadHash string) string {
+	var canonicalHeaders strings.Builder
+	for _, h := range headers {
+		value := req.Header.Get(h)
+		if h == "host" {
+			value = req.URL.Host
+			if req.Host != "" {
+				value = req.Host
+			}
+		}
+		canonicalHeaders.WriteString(h + ":" + strings.Join(strings.Fields(value), " ") + "\n")
+	}
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+
+	return strings.Join([]string{
+		req.Method,
+		path,
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		strings.Join(headers, ";"),
+		payloadHash,
+	}, "\n")
+}
+
+func canonicalQuery(q url.Values) string {
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, k := range keys {
+		values := append([]string{}, q[k]...)
+		sort.Strings(values)
+		for _, v := range values {
+			parts = append(parts, escape(k)+"="+escape(v))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+func escape(s string) string {
+	return strings.Replace(url.QueryEscape(s), "+", "%20", -1)
+}
+
+func signature(creds Credentials, t time.Time, region, service, canonical string) string {
+	stringToSign := strings.Join([]string{
+		algorithm,
+		t.Format(timeFormat),
+		credentialScope(t, region, service),
+		hashHex([]byte(canonical)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+creds.SecretAccessKey), t.Format(dateFormat))
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func hashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,86 @@
+package eks
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type SigV4Suite struct {
+	suite.Suite
+}
+
+func TestSigV4Suite(t *testing.T) {
+	suite.Run(t, new(SigV4Suite))
+}
+
+var testCreds = Credentials{
+	AccessKeyID:     "AKIDEXAMPLE",
+	SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+}
+
+var testTime = time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+
+// Cases from the AWS Signature Version 4 test suite.
+func (suite SigV4Suite) TestSign() {
+	cases := []struct {
+		name     string
+		url      string
+		expected string
+	}{
+		{
+			name:     "get-vanilla",
+			url:      "https://example.amazonaws.com/",
+			expected: "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		{
+			name:     "get-vanilla-query-order-key-case",
+			url:      "https://example.amazonaws.com/?Param2=value2&Param1=value1",
+			expected: "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500",
+		},
+	}
+
+	for _, c := range cases {
+		req, err := http.NewRequest(http.MethodGet, c.url, nil)
+		suite.Require().NoError(err)
+		Sign(req, nil, testCreds, "us-east-1", "service", testTime)
+		suite.Equal(c.expected, req.Header.Get("Authorization"), c.name)
+		suite.Equal("20150830T123600Z", req.Header.Get("X-Amz-Date"), c.name)
+	}
+}
+
+func (suite SigV4Suite) TestSignSessionToken() {
+	creds := testCreds
+	creds.SessionToken = "token"
+
+	req, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	suite.Require().NoError(err)
+	Sign(req, nil, creds, "us-east-1", "service", testTime)
+	suite.Equal("token", req.Header.Get("X-Amz-Security-Token"))
+	suite.Contains(req.Header.Get("Authorization"), "SignedHeaders=host;x-amz-date;x-amz-security-token,")
+}
+
+func (suite SigV4Suite) TestPresign() {
+	req, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/?Action=Test", nil)
+	suite.Require().NoError(err)
+	req.Header.Set("x-k8s-aws-id", "prod")
+	Presign(req, testCreds, "us-east-1", "service", time.Minute, testTime)
+
+	q := req.URL.Query()
+	suite.Equal("AWS4-HMAC-SHA256", q.Get("X-Amz-Algorithm"))
+	suite.Equal("AKIDEXAMPLE/20150830/us-east-1/service/aws4_request", q.Get("X-Amz-Credential"))
+	suite.Equal("20150830T123600Z", q.Get("X-Amz-Date"))
+	suite.Equal("60", q.Get("X-Amz-Expires"))
+	suite.Equal("host;x-k8s-aws-id", q.Get("X-Amz-SignedHeaders"))
+	suite.Len(q.Get("X-Amz-Signature"), 64)
+	suite.True(strings.HasSuffix(req.URL.RawQuery, "&X-Amz-Signature="+q.Get("X-Amz-Signature")))
+}
+
+func (suite SigV4Suite) TestCanonicalQuery() {
+	req, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/?b=2&a=x%20y&a=1&c=~+", nil)
+	suite.Require().NoError(err)
+	suite.Equal("a=1&a=x%20y&b=2&c=~%20", canonicalQuery(req.URL.Query()))
+}
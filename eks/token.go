@@ -0,0 +1,28 @@
+package eks
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	tokenPrefix  = "k8s-aws-v1."
+	tokenExpires = 14 * time.Minute
+)
+
+// Token returns a bearer token for the cluster the same way
+// `aws eks get-token` does: a presigned sts:GetCallerIdentity URL.
+func Token(creds Credentials, region, cluster string, t time.Time) (string, time.Time, error) {
+	url := fmt.Sprintf("https://sts.%s.%s/?Action=GetCallerIdentity&Version=2011-06-15", region, DNSSuffix(region))
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	req.Header.Set("x-k8s-aws-id", cluster)
+
+	Presign(req, creds, region, "sts", 60*time.Second, t)
+	token := tokenPrefix + base64.RawURLEncoding.EncodeToString([]byte(req.URL.String()))
+	return token, t.Add(tokenExpires), nil
+}
@@ -0,0 +1,35 @@
+package eks
+
+import (
+	"encoding/base64"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TokenSuite struct {
+	suite.Suite
+}
+
+func TestTokenSuite(t *testing.T) {
+	suite.Run(t, new(TokenSuite))
+}
+
+func (suite TokenSuite) TestToken() {
+	token, expires, err := Token(testCreds, "us-west-2", "prod", testTime)
+	suite.Require().NoError(err)
+	suite.Equal(testTime.Add(14*time.Minute), expires)
+	suite.True(strings.HasPrefix(token, "k8s-aws-v1."))
+
+	raw, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(token, "k8s-aws-v1."))
+	suite.Require().NoError(err)
+	u, err := url.Parse(string(raw))
+	suite.Require().NoError(err)
+	suite.Equal("sts.us-west-2.amazonaws.com", u.Host)
+	suite.Equal("GetCallerIdentity", u.Query().Get("Action"))
+	suite.Equal("host;x-k8s-aws-id", u.Query().Get("X-Amz-SignedHeaders"))
+	suite.Equal("AKIDEXAMPLE/20150830/us-west-2/sts/aws4_request", u.Query().Get("X-Amz-Credential"))
+}
@@ -6,4 +6,5 @@ require (
 	github.com/logrusorgru/aurora/v3 v3.0.0
 	github.com/spf13/cobra v1.1.1
 	github.com/stretchr/testify v1.6.1
+	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c
 )
@@ -0,0 +1,131 @@
+package kubeconfig
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// pathKeys are the cluster and user fields kubectl resolves relative to the
+// file they are defined in.
+var pathKeys = []string{"certificate-authority", "client-certificate", "client-key", "tokenFile"}
+
+var ErrClusterNotFound = errors.New("kube cluster not found")
+var ErrUserNotFound = errors.New("kube user not found")
+
+// Extract writes a kubeconfig to path holding only the named context, with
+// its cluster and user, as the current context. namespace, when set,
+// replaces the context's namespace. Entries are looked up in the merged
+// kubeconfig files, the first file to define one wins, and relative file
+// paths in them are made absolute, so the extracted file works on its own.
+func Extract(name, namespace, path string) error {
+	contexts := map[string]map[string]interface{}{}
+	clusters := map[string]map[string]interface{}{}
+	users := map[string]map[string]interface{}{}
+
+	for _, f := range Files() {
+		data, err := ioutil.ReadFile(f)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		doc := map[string]interface{}{}
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return fmt.Errorf("%s: %w", f, err)
+		}
+		dir, err := filepath.Abs(filepath.Dir(f))
+		if err != nil {
+			return err
+		}
+
+		collectNamed(doc, "contexts", "", "", contexts)
+		collectNamed(doc, "clusters", "cluster", dir, clusters)
+		collectNamed(doc, "users", "user", dir, users)
+	}
+
+	context := contexts[name]
+	if context == nil {
+		return fmt.Errorf("%w: %s", ErrContextNotFound, name)
+	}
+	c, _ := context["context"].(map[string]interface{})
+	if namespace != "" {
+		if c == nil {
+			c = map[string]interface{}{}
+			context["context"] = c
+		}
+		c["namespace"] = namespace
+	}
+
+	doc := map[string]interface{}{
+		"apiVersion":      "v1",
+		"kind":            "Config",
+		"current-context": name,
+		"contexts":        []interface{}{context},
+		"clusters":        []interface{}{},
+		"users":           []interface{}{},
+	}
+	if clusterName, _ := c["cluster"].(string); clusterName != "" {
+		cluster := clusters[clusterName]
+		if cluster == nil {
+			return fmt.Errorf("%w: %s, used by context %s", ErrClusterNotFound, clusterName, name)
+		}
+		doc["clusters"] = []interface{}{cluster}
+	}
+	if userName, _ := c["user"].(string); userName != "" {
+		user := users[userName]
+		if user == nil {
+			return fmt.Errorf("%w: %s, used by context %s", ErrUserNotFound, userName, name)
+		}
+		doc["users"] = []interface{}{user}
+	}
+
+	out, err := yaml.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, out, 0600)
+}
+
+// collectNamed adds the entries of list in doc to found, unless an earlier
+// file already defined them, with the relative paths under key made
+// absolute to dir.
+func collectNamed(doc map[string]interface{}, list, key, dir string, found map[string]map[string]interface{}) {
+	items, _ := doc[list].([]interface{})
+	for _, item := range items {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := m["name"].(string)
+		if _, ok := found[name]; ok || name == "" {
+			continue
+		}
+		if key != "" {
+			m = absolutePaths(m, key, dir)
+		}
+		found[name] = m
+	}
+}
+
+func absolutePaths(entry map[string]interface{}, key, dir string) map[string]interface{} {
+	if entry == nil {
+		return nil
+	}
+	if values, ok := entry[key].(map[string]interface{}); ok {
+		for _, k := range pathKeys {
+			if p, ok := values[k].(string); ok && p != "" && !filepath.IsAbs(p) {
+				values[k] = filepath.Join(dir, p)
+			}
+		}
+	}
+	return entry
+}
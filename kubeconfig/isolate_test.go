@@ -0,0 +1,170 @@
+package kubeconfig
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/yaml.v3"
+)
+
+type IsolateSuite struct {
+	suite.Suite
+	dir string
+}
+
+func TestIsolateSuite(t *testing.T) {
+	suite.Run(t, new(IsolateSuite))
+}
+
+func (suite *IsolateSuite) SetupTest() {
+	dir, err := ioutil.TempDir("", "kubeconfig")
+	suite.Require().NoError(err)
+	suite.dir = dir
+	os.Setenv("KUBECONFIG", strings.Join([]string{filepath.Join("testdata", "config"), filepath.Join("testdata", "extra")}, string(filepath.ListSeparator)))
+}
+
+func (suite *IsolateSuite) TearDownTest() {
+	os.RemoveAll(suite.dir)
+	os.Unsetenv("KUBECONFIG")
+}
+
+func (suite *IsolateSuite) TestExtract() {
+	path := filepath.Join(suite.dir, "kube", "prod.yaml")
+	suite.Require().NoError(Extract("prod", "", path))
+
+	info, err := os.Stat(path)
+	suite.Require().NoError(err)
+	suite.Equal(os.FileMode(0600), info.Mode().Perm())
+
+	os.Setenv("KUBECONFIG", path)
+	contexts, err := Load()
+	suite.NoError(err)
+	suite.Equal([]Context{{
+		Name:      "prod",
+		Cluster:   "arn:aws:eks:us-east-1:123456789012:cluster/prod",
+		User:      "arn:aws:eks:us-east-1:123456789012:cluster/prod",
+		Namespace: "payments",
+		Server:    "https://ABCDEF.gr7.us-east-1.eks.amazonaws.com",
+	}}, contexts)
+
+	var doc struct {
+		CurrentContext string `yaml:"current-context"`
+		Users          []struct {
+			Name string `yaml:"name"`
+			User struct {
+				Exec struct {
+					Command string `yaml:"command"`
+				} `yaml:"exec"`
+			} `yaml:"user"`
+		} `yaml:"users"`
+	}
+	data, err := ioutil.ReadFile(path)
+	suite.Require().NoError(err)
+	suite.Require().NoError(yaml.Unmarshal(data, &doc))
+	suite.Equal("prod", doc.CurrentContext)
+	suite.Len(doc.Users, 1)
+	suite.Equal("aws", doc.Users[0].User.Exec.Command)
+}
+
+func (suite *IsolateSuite) TestExtractNamespaceAndMerge() {
+	path := filepath.Join(suite.dir, "kind.yaml")
+	suite.Require().NoError(Extract("kind-local", "tools", path))
+
+	os.Setenv("KUBECONFIG", path)
+	c, err := Find("kind-local")
+	suite.NoError(err)
+	suite.Equal("tools", c.Namespace)
+	// the first file to define the cluster wins
+	suite.Equal("https://127.0.0.1:6443", c.Server)
+}
+
+func (suite *IsolateSuite) TestExtractRelativePaths() {
+	src := filepath.Join(suite.dir, "src", "config")
+	suite.Require().NoError(os.MkdirAll(filepath.Dir(src), 0755))
+	suite.Require().NoError(ioutil.WriteFile(src, []byte(`
+clusters:
+- name: local
+  cluster:
+    server: https://127.0.0.1:6443
+    certificate-authority: certs/ca.crt
+contexts:
+- name: local
+  context:
+    cluster: local
+    user: local
+users:
+- name: local
+  user:
+    client-certificate: /etc/client.crt
+    client-key: certs/client.key
+`), 0600))
+	os.Setenv("KUBECONFIG", src)
+
+	path := filepath.Join(suite.dir, "local.yaml")
+	suite.Require().NoError(Extract("local", "", path))
+	data, err := ioutil.ReadFile(path)
+	suite.Require().NoError(err)
+	suite.Contains(string(data), "certificate-authority: "+filepath.Join(suite.dir, "src", "certs", "ca.crt"))
+	suite.Contains(string(data), "client-key: "+filepath.Join(suite.dir, "src", "certs", "client.key"))
+	suite.Contains(string(data), "client-certificate: /etc/client.crt")
+}
+
+func (suite *IsolateSuite) TestExtractMissing() {
+	err := Extract("missing", "", filepath.Join(suite.dir, "missing.yaml"))
+	suite.True(errors.Is(err, ErrContextNotFound))
+	suite.NoFileExists(filepath.Join(suite.dir, "missing.yaml"))
+}
+
+func (suite *IsolateSuite) TestExtractAcrossFiles() {
+	first := filepath.Join(suite.dir, "a")
+	suite.Require().NoError(ioutil.WriteFile(first, []byte(`
+clusters:
+- name: shared
+  cluster:
+    server: https://10.0.0.1:6443
+users:
+- name: admin
+  user:
+    token: secret
+`), 0600))
+	second := filepath.Join(suite.dir, "b")
+	suite.Require().NoError(ioutil.WriteFile(second, []byte(`
+clusters:
+- name: shared
+  cluster:
+    server: https://10.0.0.2:6443
+contexts:
+- name: team
+  context:
+    cluster: shared
+    user: admin
+- name: broken
+  context:
+    cluster: missing
+    user: admin
+- name: anonymous
+  context:
+    cluster: shared
+    user: nobody
+`), 0600))
+	os.Setenv("KUBECONFIG", strings.Join([]string{first, second}, string(filepath.ListSeparator)))
+
+	path := filepath.Join(suite.dir, "team.yaml")
+	suite.Require().NoError(Extract("team", "", path))
+	data, err := ioutil.ReadFile(path)
+	suite.Require().NoError(err)
+	suite.Contains(string(data), "server: https://10.0.0.1:6443")
+	suite.Contains(string(data), "token: secret")
+
+	err = Extract("broken", "", filepath.Join(suite.dir, "broken.yaml"))
+	suite.True(errors.Is(err, ErrClusterNotFound))
+	suite.NoFileExists(filepath.Join(suite.dir, "broken.yaml"))
+
+	err = Extract("anonymous", "", filepath.Join(suite.dir, "anonymous.yaml"))
+	suite.True(errors.Is(err, ErrUserNotFound))
+}
@@ -0,0 +1,161 @@
+package kubeconfig
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var ErrNoKubeconfig = errors.New("no kubeconfig file found")
+var ErrContextNotFound = errors.New("kube context not found")
+
+type Context struct {
+	Name      string
+	Cluster   string
+	User      string
+	Namespace string
+	Server    string
+}
+
+type file struct {
+	CurrentContext string         `yaml:"current-context"`
+	Contexts       []namedContext `yaml:"contexts"`
+	Clusters       []namedCluster `yaml:"clusters"`
+}
+
+type namedContext struct {
+	Name    string `yaml:"name"`
+	Context struct {
+		Cluster   string `yaml:"cluster"`
+		User      string `yaml:"user"`
+		Namespace string `yaml:"namespace"`
+	} `yaml:"context"`
+}
+
+type namedCluster struct {
+	Name    string `yaml:"name"`
+	Cluster struct {
+		Server string `yaml:"server"`
+	} `yaml:"cluster"`
+}
+
+// Files returns the kubeconfig files in the order kubectl reads them:
+// every entry of $KUBECONFIG, or ~/.kube/config when it names none. The
+// first file is the one kubectl writes to.
+func Files() []string {
+	var files []string
+	for _, f := range filepath.SplitList(os.Getenv("KUBECONFIG")) {
+		if f != "" {
+			files = append(files, f)
+		}
+	}
+	if len(files) > 0 {
+		return files
+	}
+	home, _ := os.UserHomeDir()
+	return []string{filepath.Join(home, ".kube", "config")}
+}
+
+// Load merges every kubeconfig file the way kubectl does, the first file
+// to define a context or cluster wins. Missing files are skipped.
+func Load() ([]Context, error) {
+	var contexts []Context
+	seenContexts := map[string]bool{}
+	servers := map[string]string{}
+	loaded := false
+
+	for _, path := range Files() {
+		f, err := readFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		loaded = true
+
+		for _, c := range f.Contexts {
+			if seenContexts[c.Name] {
+				continue
+			}
+			seenContexts[c.Name] = true
+			contexts = append(contexts, Context{
+				Name:      c.Name,
+				Cluster:   c.Context.Cluster,
+				User:      c.Context.User,
+				Namespace: c.Context.Namespace,
+			})
+		}
+		for _, c := range f.Clusters {
+			if _, ok := servers[c.Name]; !ok {
+				servers[c.Name] = c.Cluster.Server
+			}
+		}
+	}
+
+	if !loaded {
+		return nil, ErrNoKubeconfig
+	}
+
+	for i := range contexts {
+		contexts[i].Server = servers[contexts[i].Cluster]
+	}
+	return contexts, nil
+}
+
+// Find returns the merged context with the given name.
+func Find(name string) (Context, error) {
+	contexts, err := Load()
+	if err != nil {
+		return Context{}, err
+	}
+	for _, c := range contexts {
+		if c.Name == name {
+			return c, nil
+		}
+	}
+	return Context{}, fmt.Errorf("%w: %s", ErrContextNotFound, name)
+}
+
+func readFile(path string) (file, error) {
+	var f file
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return f, err
+	}
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return f, fmt.Errorf("%s: %w", path, err)
+	}
+	return f, nil
+}
+
+// IsEKS reports whether the context points to an EKS cluster.
+func (c Context) IsEKS() bool {
+	if strings.HasPrefix(c.Cluster, "arn:") && strings.Contains(c.Cluster, ":eks:") {
+		return true
+	}
+	u, err := url.Parse(c.Server)
+	if err != nil {
+		return false
+	}
+	host := u.Hostname()
+	return strings.HasSuffix(host, ".eks.amazonaws.com") || strings.HasSuffix(host, ".eks.amazonaws.com.cn")
+}
+
+// Label describes the context as "context → cluster (namespace)".
+func (c Context) Label() string {
+	label := c.Name
+	if c.Cluster != "" && c.Cluster != c.Name {
+		label += " → " + c.Cluster
+	}
+	if c.Namespace != "" {
+		label += " (" + c.Namespace + ")"
+	}
+	return label
+}
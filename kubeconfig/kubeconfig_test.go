@@ -0,0 +1,103 @@
+package kubeconfig
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type KubeconfigSuite struct {
+	suite.Suite
+}
+
+func TestKubeconfigSuite(t *testing.T) {
+	suite.Run(t, new(KubeconfigSuite))
+}
+
+func (suite KubeconfigSuite) TearDownTest() {
+	os.Unsetenv("KUBECONFIG")
+}
+
+func (suite KubeconfigSuite) TestFiles() {
+	os.Setenv("KUBECONFIG", strings.Join([]string{"a", "", "b"}, string(os.PathListSeparator)))
+	suite.Equal([]string{"a", "b"}, Files())
+
+	os.Unsetenv("KUBECONFIG")
+	home, err := os.UserHomeDir()
+	suite.Require().NoError(err)
+	suite.Equal([]string{filepath.Join(home, ".kube", "config")}, Files())
+
+	os.Setenv("KUBECONFIG", string(os.PathListSeparator))
+	suite.Equal([]string{filepath.Join(home, ".kube", "config")}, Files())
+}
+
+func (suite KubeconfigSuite) TestLoadMerged() {
+	os.Setenv("KUBECONFIG", strings.Join([]string{
+		filepath.Join("testdata", "config"),
+		filepath.Join("testdata", "missing"),
+		filepath.Join("testdata", "extra"),
+	}, string(os.PathListSeparator)))
+
+	contexts, err := Load()
+	suite.Require().NoError(err)
+	suite.Equal([]Context{
+		{
+			Name:      "prod",
+			Cluster:   "arn:aws:eks:us-east-1:123456789012:cluster/prod",
+			User:      "arn:aws:eks:us-east-1:123456789012:cluster/prod",
+			Namespace: "payments",
+			Server:    "https://ABCDEF.gr7.us-east-1.eks.amazonaws.com",
+		},
+		{Name: "kind-local", Cluster: "kind-local", User: "kind-local", Server: "https://127.0.0.1:6443"},
+		{Name: "dev", Cluster: "dev", User: "dev", Server: "https://0123.yl4.cn-north-1.eks.amazonaws.com.cn"},
+	}, contexts)
+}
+
+func (suite KubeconfigSuite) TestFind() {
+	os.Setenv("KUBECONFIG", filepath.Join("testdata", "config"))
+
+	c, err := Find("prod")
+	suite.NoError(err)
+	suite.Equal("payments", c.Namespace)
+
+	_, err = Find("missing")
+	suite.True(errors.Is(err, ErrContextNotFound))
+}
+
+func (suite KubeconfigSuite) TestLoadMissing() {
+	os.Setenv("KUBECONFIG", filepath.Join("testdata", "missing"))
+	_, err := Load()
+	suite.Equal(ErrNoKubeconfig, err)
+}
+
+func (suite KubeconfigSuite) TestLoadInvalid() {
+	f, err := ioutil.TempFile("", "kubeconfig")
+	suite.Require().NoError(err)
+	defer os.Remove(f.Name())
+	_, err = f.WriteString("contexts: {")
+	suite.Require().NoError(err)
+	f.Close()
+
+	os.Setenv("KUBECONFIG", f.Name())
+	_, err = Load()
+	suite.Error(err)
+}
+
+func (suite KubeconfigSuite) TestIsEKS() {
+	suite.True(Context{Cluster: "arn:aws:eks:us-east-1:123456789012:cluster/prod"}.IsEKS())
+	suite.True(Context{Cluster: "prod", Server: "https://ABCDEF.gr7.us-east-1.eks.amazonaws.com"}.IsEKS())
+	suite.True(Context{Cluster: "dev", Server: "https://0123.yl4.cn-north-1.eks.amazonaws.com.cn"}.IsEKS())
+	suite.False(Context{Cluster: "kind-local", Server: "https://127.0.0.1:6443"}.IsEKS())
+	suite.False(Context{Cluster: "bad", Server: "://"}.IsEKS())
+}
+
+func (suite KubeconfigSuite) TestLabel() {
+	suite.Equal("prod → arn:aws:eks:us-east-1:123456789012:cluster/prod (payments)", Context{Name: "prod", Cluster: "arn:aws:eks:us-east-1:123456789012:cluster/prod", Namespace: "payments"}.Label())
+	suite.Equal("kind-local", Context{Name: "kind-local", Cluster: "kind-local"}.Label())
+	suite.Equal("minikube (default)", Context{Name: "minikube", Namespace: "default"}.Label())
+}
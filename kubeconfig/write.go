@@ -0,0 +1,175 @@
+package kubeconfig
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+const execAPIVersion = "client.authentication.k8s.io/v1beta1"
+
+var ErrNotMapping = errors.New("kubeconfig is not a yaml mapping")
+
+// Entry is a context together with the cluster and user it points to.
+type Entry struct {
+	Context   string
+	Cluster   string
+	Server    string
+	CAData    string
+	User      string
+	Namespace string
+	Exec      Exec
+}
+
+// Exec is the credential plugin the user entry runs to get a token.
+type Exec struct {
+	Command string
+	Args    []string
+	Env     map[string]string
+}
+
+// Upsert adds e to the kubeconfig file at path, replacing entries with the
+// same names, and makes it the current context. The rest of the file, its
+// order and comments included, is left as it is.
+func Upsert(path string, e Entry) error {
+	var doc yaml.Node
+	data, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	if len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("%s: %w", path, ErrNotMapping)
+	}
+
+	setValue(root, "apiVersion", scalar("v1"))
+	setValue(root, "kind", scalar("Config"))
+	setValue(root, "current-context", scalar(e.Context))
+
+	cluster := map[string]interface{}{"server": e.Server}
+	if e.CAData != "" {
+		cluster["certificate-authority-data"] = e.CAData
+	}
+	if err := upsertNamed(root, "clusters", e.Cluster, "cluster", cluster); err != nil {
+		return err
+	}
+
+	context := map[string]interface{}{"cluster": e.Cluster, "user": e.User}
+	if e.Namespace != "" {
+		context["namespace"] = e.Namespace
+	}
+	if err := upsertNamed(root, "contexts", e.Context, "context", context); err != nil {
+		return err
+	}
+
+	exec := map[string]interface{}{
+		"apiVersion": execAPIVersion,
+		"command":    e.Exec.Command,
+		"args":       e.Exec.Args,
+	}
+	if len(e.Exec.Env) > 0 {
+		var names []string
+		for name := range e.Exec.Env {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		var env []interface{}
+		for _, name := range names {
+			env = append(env, map[string]interface{}{"name": name, "value": e.Exec.Env[name]})
+		}
+		exec["env"] = env
+	}
+	if err := upsertNamed(root, "users", e.User, "user", map[string]interface{}{"exec": exec}); err != nil {
+		return err
+	}
+
+	var out bytes.Buffer
+	enc := yaml.NewEncoder(&out)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, out.Bytes(), 0600)
+}
+
+// upsertNamed replaces the item called name in the list under the given key
+// of root, or appends it when there is none.
+func upsertNamed(root *yaml.Node, list, name, key string, value map[string]interface{}) error {
+	item, err := toNode(map[string]interface{}{"name": name, key: value})
+	if err != nil {
+		return err
+	}
+	items := mappingValue(root, list)
+	if items == nil || items.Kind != yaml.SequenceNode {
+		items = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		setValue(root, list, items)
+	}
+	for i, existing := range items.Content {
+		if n := mappingValue(existing, "name"); n != nil && n.Value == name {
+			items.Content[i] = item
+			return nil
+		}
+	}
+	items.Content = append(items.Content, item)
+	return nil
+}
+
+// mappingValue returns the value of key in the mapping node m, or nil.
+func mappingValue(m *yaml.Node, key string) *yaml.Node {
+	if m.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// setValue sets key in the mapping node m to value, keeping its place when
+// the key is already there.
+func setValue(m *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			m.Content[i+1] = value
+			return
+		}
+	}
+	m.Content = append(m.Content, scalar(key), value)
+}
+
+func scalar(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
+
+// toNode returns v as a yaml node.
+func toNode(v interface{}) (*yaml.Node, error) {
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return doc.Content[0], nil
+}
@@ -0,0 +1,131 @@
+package kubeconfig
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type WriteSuite struct {
+	suite.Suite
+	dir string
+}
+
+func TestWriteSuite(t *testing.T) {
+	suite.Run(t, new(WriteSuite))
+}
+
+func (suite *WriteSuite) SetupTest() {
+	dir, err := ioutil.TempDir("", "kubeconfig")
+	suite.Require().NoError(err)
+	suite.dir = dir
+}
+
+func (suite *WriteSuite) TearDownTest() {
+	os.RemoveAll(suite.dir)
+	os.Unsetenv("KUBECONFIG")
+}
+
+func (suite *WriteSuite) TestUpsert() {
+	path := filepath.Join(suite.dir, ".kube", "config")
+	data, err := ioutil.ReadFile(filepath.Join("testdata", "config"))
+	suite.Require().NoError(err)
+	suite.Require().NoError(os.MkdirAll(filepath.Dir(path), 0755))
+	suite.Require().NoError(ioutil.WriteFile(path, data, 0600))
+
+	arn := "arn:aws:eks:us-east-1:123456789012:cluster/prod"
+	e := Entry{
+		Context: "prod",
+		Cluster: arn,
+		Server:  "https://NEW.gr7.us-east-1.eks.amazonaws.com",
+		CAData:  "bmV3",
+		User:    arn,
+		Exec: Exec{
+			Command: "ekalias",
+			Args:    []string{"token", "--cluster-name", "prod", "--region", "us-east-1"},
+			Env:     map[string]string{"AWS_PROFILE": "prod-admin"},
+		},
+	}
+	suite.NoError(Upsert(path, e))
+
+	os.Setenv("KUBECONFIG", path)
+	contexts, err := Load()
+	suite.Require().NoError(err)
+	suite.Equal([]Context{
+		{Name: "prod", Cluster: arn, User: arn, Server: "https://NEW.gr7.us-east-1.eks.amazonaws.com"},
+		{Name: "kind-local", Cluster: "kind-local", User: "kind-local", Server: "https://127.0.0.1:6443"},
+	}, contexts)
+
+	out, err := ioutil.ReadFile(path)
+	suite.Require().NoError(err)
+	suite.Contains(string(out), "current-context: prod")
+	suite.Contains(string(out), "name: AWS_PROFILE")
+	suite.Contains(string(out), "command: ekalias")
+	suite.Contains(string(out), "certificate-authority-data: bmV3")
+}
+
+func (suite *WriteSuite) TestUpsertKeepsLayout() {
+	path := filepath.Join(suite.dir, "config")
+	content := `# managed by hand
+kind: Config
+apiVersion: v1
+preferences: {}
+contexts:
+- context:
+    cluster: kind-local
+    user: kind-local
+  name: kind-local # local cluster
+current-context: kind-local
+clusters:
+- cluster:
+    server: https://127.0.0.1:6443
+  name: kind-local
+users:
+- name: kind-local
+  user:
+    token: abc
+`
+	suite.Require().NoError(ioutil.WriteFile(path, []byte(content), 0600))
+
+	suite.NoError(Upsert(path, Entry{Context: "dev", Cluster: "dev", Server: "https://dev", User: "dev", Exec: Exec{Command: "ekalias"}}))
+
+	out, err := ioutil.ReadFile(path)
+	suite.Require().NoError(err)
+	suite.True(strings.HasPrefix(string(out), `# managed by hand
+kind: Config
+apiVersion: v1
+preferences: {}
+contexts:
+- context:
+    cluster: kind-local
+    user: kind-local
+  name: kind-local # local cluster
+- context:
+    cluster: dev
+    user: dev
+  name: dev
+current-context: dev
+clusters:
+- cluster:
+    server: https://127.0.0.1:6443
+  name: kind-local
+`), string(out))
+}
+
+func (suite *WriteSuite) TestUpsertNewFile() {
+	path := filepath.Join(suite.dir, "config")
+	suite.NoError(Upsert(path, Entry{Context: "dev", Cluster: "dev", Server: "https://dev", User: "dev", Namespace: "apps", Exec: Exec{Command: "ekalias"}}))
+
+	os.Setenv("KUBECONFIG", path)
+	contexts, err := Load()
+	suite.Require().NoError(err)
+	suite.Equal([]Context{{Name: "dev", Cluster: "dev", User: "dev", Namespace: "apps", Server: "https://dev"}}, contexts)
+
+	info, err := os.Stat(path)
+	suite.Require().NoError(err)
+	suite.Equal(os.FileMode(0600), info.Mode().Perm())
+}
@@ -0,0 +1,80 @@
+package kubectl
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/eiladin/ekalias/aws"
+	"github.com/eiladin/ekalias/kubeconfig"
+)
+
+var ErrAccountMismatch = errors.New("kube context belongs to another AWS account than the profile")
+
+// profileAccount returns the account and region of the selected profile.
+// The account is empty when contexts are not checked against it: when no
+// profile is selected, when the check is skipped, when there are no EKS
+// contexts to check, or when the account cannot be found. The region is
+// empty when the profile has none.
+func (k Kubectl) profileAccount(a aws.AWS, contexts []kubeconfig.Context) (string, string) {
+	if k.opts.AWS.Profile == "" || k.opts.SkipAccountCheck || len(inAccount(contexts, "")) == 0 {
+		return "", ""
+	}
+	account, err := a.AccountID()
+	if err != nil {
+		k.executor.Warn(fmt.Sprintf("unable to find the account of profile %s, kube contexts are not checked against it: %s", k.opts.AWS.Profile, err))
+		return "", ""
+	}
+	return account, a.DefaultRegion()
+}
+
+// confirmAccount reports whether c may be used with the selected profile.
+// EKS contexts of another account than the profile's need to be confirmed,
+// which fails in non-interactive mode. A cluster in another region than the
+// profile's is only warned about, as the profile's region is a default that
+// the kube context does not use.
+func (k Kubectl) confirmAccount(c kubeconfig.Context, account, region string) (bool, error) {
+	arn, ok := clusterARN(c)
+	if account == "" || !ok {
+		return true, nil
+	}
+	if arn.AccountID == account {
+		if region != "" && arn.Region != region {
+			k.executor.Warn(fmt.Sprintf("context %s is cluster %s in region %s, profile %s uses region %s", c.Name, arn.Name, arn.Region, k.opts.AWS.Profile, region))
+		}
+		return true, nil
+	}
+
+	mismatch := fmt.Sprintf("context %s is cluster %s in account %s (%s), profile %s is account %s", c.Name, arn.Name, arn.AccountID, arn.Region, k.opts.AWS.Profile, account)
+	if region != "" {
+		mismatch += fmt.Sprintf(" (%s)", region)
+	}
+	if k.opts.NonInteractive {
+		return false, fmt.Errorf("%w: %s, use a context of account %s or --skip-account-check", ErrAccountMismatch, mismatch, account)
+	}
+
+	k.executor.Warn(mismatch)
+	r, err := k.executor.PromptInput("Use it anyway? (only 'yes' will be accepted to approve): ")
+	if err != nil {
+		return false, err
+	}
+	return r == "yes", nil
+}
+
+// inAccount returns the EKS contexts of account, or every EKS context when
+// account is empty.
+func inAccount(contexts []kubeconfig.Context, account string) []kubeconfig.Context {
+	var found []kubeconfig.Context
+	for _, c := range contexts {
+		if arn, ok := clusterARN(c); ok && (account == "" || arn.AccountID == account) {
+			found = append(found, c)
+		}
+	}
+	return found
+}
+
+func clusterARN(c kubeconfig.Context) (aws.ClusterARN, bool) {
+	if !c.IsEKS() {
+		return aws.ClusterARN{}, false
+	}
+	return aws.ParseClusterARN(c.Cluster)
+}
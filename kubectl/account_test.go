@@ -0,0 +1,149 @@
+//go:build test
+// +build test
+
+package kubectl
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/eiladin/ekalias/aws"
+	"github.com/eiladin/ekalias/console"
+	"github.com/eiladin/ekalias/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+const prodLabel = "prod → arn:aws:eks:us-east-1:123456789012:cluster/prod (payments)"
+
+type AccountSuite struct {
+	suite.Suite
+	dir string
+}
+
+func TestAccountSuite(t *testing.T) {
+	suite.Run(t, new(AccountSuite))
+}
+
+func (suite *AccountSuite) SetupTest() {
+	dir, err := ioutil.TempDir("", "ekalias-account")
+	suite.Require().NoError(err)
+	suite.dir = dir
+
+	config := "[profile dev]\nsso_account_id = 999999999999\n\n[profile prod]\nsso_account_id = 123456789012\nregion = us-east-1\n\n[profile prod-eu]\nsso_account_id = 123456789012\nregion = eu-west-1\n"
+	suite.Require().NoError(ioutil.WriteFile(filepath.Join(dir, "config"), []byte(config), 0600))
+	os.Setenv("AWS_CONFIG_FILE", filepath.Join(dir, "config"))
+	os.Setenv("KUBECONFIG", filepath.Join("testdata", "config"))
+	os.Unsetenv("AWS_REGION")
+	os.Unsetenv("AWS_DEFAULT_REGION")
+}
+
+func (suite *AccountSuite) TearDownTest() {
+	os.RemoveAll(suite.dir)
+	os.Unsetenv("AWS_CONFIG_FILE")
+	os.Unsetenv("KUBECONFIG")
+}
+
+func (suite *AccountSuite) TestSameAccount() {
+	e := new(mocks.Executor)
+	e.On("SelectValueFromList", mock.Anything, "Kube Context", mock.Anything).Return(prodLabel, nil)
+
+	res, err := New(e).WithOptions(Options{AWS: aws.Options{Profile: "prod"}}).SelectContext()
+	suite.NoError(err)
+	suite.Equal("prod", res)
+	e.AssertNotCalled(suite.T(), "Warn", mock.Anything)
+}
+
+func (suite *AccountSuite) TestOtherRegion() {
+	for _, opts := range []Options{{AWS: aws.Options{Profile: "prod-eu"}}, {Context: "prod", NonInteractive: true, AWS: aws.Options{Profile: "prod-eu"}}} {
+		e := new(mocks.Executor)
+		e.On("SelectValueFromList", mock.Anything, "Kube Context", mock.Anything).Return(prodLabel, nil)
+		e.On("Warn", mock.Anything).Return()
+
+		res, err := New(e).WithOptions(opts).SelectContext()
+		suite.NoError(err)
+		suite.Equal("prod", res)
+		e.AssertCalled(suite.T(), "Warn", "context prod is cluster prod in region us-east-1, profile prod-eu uses region eu-west-1")
+		e.AssertNotCalled(suite.T(), "PromptInput", mock.Anything)
+	}
+}
+
+func (suite *AccountSuite) TestOtherAccountConfirmed() {
+	e := new(mocks.Executor)
+	e.On("SelectValueFromList", mock.Anything, "Kube Context", mock.Anything).Return(prodLabel, nil)
+	e.On("Warn", mock.Anything).Return()
+	e.On("PromptInput", mock.Anything).Return("yes", nil)
+
+	res, err := New(e).WithOptions(Options{AWS: aws.Options{Profile: "dev"}}).SelectContext()
+	suite.NoError(err)
+	suite.Equal("prod", res)
+	e.AssertCalled(suite.T(), "Warn", "context prod is cluster prod in account 123456789012 (us-east-1), profile dev is account 999999999999")
+}
+
+func (suite *AccountSuite) TestOtherAccountFiltered() {
+	e := new(mocks.Executor)
+	e.On("SelectValueFromList", []string{prodLabel, "kind-local"}, "Kube Context", mock.Anything).Return(prodLabel, nil).Once()
+	e.On("SelectValueFromList", []string{}, "Kube Context", mock.Anything).Return("dev-cluster", nil).Once()
+	e.On("Warn", mock.Anything).Return()
+	e.On("PromptInput", mock.Anything).Return("no", nil)
+
+	res, err := New(e).WithOptions(Options{AWS: aws.Options{Profile: "dev"}}).SelectContext()
+	suite.NoError(err)
+	suite.Equal("dev-cluster", res)
+	e.AssertCalled(suite.T(), "Warn", "showing only the kube contexts of account 999999999999")
+	e.AssertExpectations(suite.T())
+}
+
+func (suite *AccountSuite) TestOtherAccountBack() {
+	e := new(mocks.Executor)
+	e.On("SelectValueFromList", mock.Anything, "Kube Context", mock.Anything).Return(prodLabel, nil)
+	e.On("Warn", mock.Anything).Return()
+	e.On("PromptInput", mock.Anything).Return("", console.ErrBack)
+
+	_, err := New(e).WithOptions(Options{AWS: aws.Options{Profile: "dev"}}).SelectContext()
+	suite.True(errors.Is(err, console.ErrBack))
+}
+
+func (suite *AccountSuite) TestWithOptions() {
+	cases := []struct {
+		opts          Options
+		expected      string
+		expectedError error
+	}{
+		{opts: Options{Context: "prod", NonInteractive: true, AWS: aws.Options{Profile: "prod"}}, expected: "prod"},
+		{opts: Options{Context: "prod", NonInteractive: true, AWS: aws.Options{Profile: "dev"}}, expectedError: ErrAccountMismatch},
+		{opts: Options{Context: "prod", NonInteractive: true, SkipAccountCheck: true, AWS: aws.Options{Profile: "dev"}}, expected: "prod"},
+		{opts: Options{Context: "kind-local", NonInteractive: true, AWS: aws.Options{Profile: "dev"}}, expected: "kind-local"},
+		{opts: Options{Context: "prod", AWS: aws.Options{Profile: "dev"}}, expectedError: ErrAccountMismatch},
+	}
+
+	for _, c := range cases {
+		e := new(mocks.Executor)
+		e.On("Warn", mock.Anything).Return()
+		e.On("PromptInput", mock.Anything).Return("no", nil)
+
+		res, err := New(e).WithOptions(c.opts).SelectContext()
+		if c.expectedError != nil {
+			suite.True(errors.Is(err, c.expectedError), err)
+		} else {
+			suite.NoError(err)
+		}
+		suite.Equal(c.expected, res)
+	}
+}
+
+func (suite *AccountSuite) TestUnknownAccount() {
+	e := new(mocks.Executor)
+	e.On("FindExecutable", "aws").Return("", console.ErrCliNotFound)
+	e.On("Warn", mock.Anything).Return()
+	e.On("SelectValueFromList", mock.Anything, "Kube Context", mock.Anything).Return(prodLabel, nil)
+
+	res, err := New(e).WithOptions(Options{AWS: aws.Options{Profile: "missing"}}).SelectContext()
+	suite.NoError(err)
+	suite.Equal("prod", res)
+	e.AssertCalled(suite.T(), "Warn", "unable to find the account of profile missing, kube contexts are not checked against it: command not found on PATH")
+	e.AssertNotCalled(suite.T(), "PromptInput", mock.Anything)
+}
@@ -1,43 +1,136 @@
 package kubectl
 
 import (
+	"context"
+	"fmt"
 	"strings"
 
 	"github.com/eiladin/ekalias/aws"
 	"github.com/eiladin/ekalias/console"
+	"github.com/eiladin/ekalias/kubeconfig"
 )
 
 const executable = "kubectl"
 
+var ErrContextNotFound = kubeconfig.ErrContextNotFound
+
+// Options holds values that were provided up front and replace the matching prompts.
+// AWS is used when a new context has to be created.
+type Options struct {
+	Context          string
+	Namespace        string
+	NonInteractive   bool
+	SkipAccountCheck bool
+	AWS              aws.Options
+}
+
 type Kubectl struct {
 	executor console.Executor
+	opts     Options
+	ctx      context.Context
 }
 
 func New(e console.Executor) Kubectl {
-	return Kubectl{executor: e}
+	return Kubectl{executor: e, ctx: context.Background()}
+}
+
+func (k Kubectl) WithOptions(o Options) Kubectl {
+	k.opts = o
+	return k
+}
+
+// WithContext returns a copy of k whose commands are stopped when ctx is done.
+func (k Kubectl) WithContext(ctx context.Context) Kubectl {
+	k.ctx = ctx
+	return k
 }
 
 func (k Kubectl) FindCli() (string, error) {
 	return k.executor.FindExecutable(executable)
 }
 
-func (k Kubectl) findContexts() ([]string, error) {
+func (k Kubectl) findContexts() ([]kubeconfig.Context, error) {
+	if contexts, err := kubeconfig.Load(); err == nil && len(contexts) > 0 {
+		return contexts, nil
+	}
+
 	kubectl, err := k.FindCli()
 	if err != nil {
-		return []string{}, err
+		return []kubeconfig.Context{}, err
 	}
-	out, err := k.executor.ExecCommand(kubectl, "config", "get-contexts", "-o", "name")
+	out, err := k.executor.ExecCommand(k.ctx, kubectl, "config", "get-contexts", "-o", "name")
 	if err != nil {
-		return []string{}, err
+		return []kubeconfig.Context{}, err
 	}
-	return strings.Split(out, "\n"), nil
+
+	names := strings.Split(out, "\n")
+	contexts := make([]kubeconfig.Context, len(names))
+	for i, name := range names {
+		contexts[i] = kubeconfig.Context{Name: name}
+	}
+	return contexts, nil
 }
 
+// SelectContext picks the kube context for the alias. EKS contexts are
+// checked against the account of the selected profile, and when a context of
+// another account is not confirmed only the contexts of the profile's account
+// are offered.
 func (k Kubectl) SelectContext() (string, error) {
 	contexts, err := k.findContexts()
 	if err != nil {
 		return "", err
 	}
-	aws := aws.New(k.executor)
-	return k.executor.SelectValueFromList(contexts, "Kube Context", aws.CreateKubeContext)
+	aws := aws.New(k.executor).WithOptions(k.opts.AWS).WithContext(k.ctx)
+	account, region := k.profileAccount(aws, contexts)
+
+	switch {
+	case k.opts.Context != "":
+		for _, c := range contexts {
+			if c.Name == k.opts.Context {
+				if ok, err := k.confirmAccount(c, account, region); !ok {
+					if err == nil {
+						err = fmt.Errorf("%w: %s", ErrAccountMismatch, c.Name)
+					}
+					return "", err
+				}
+				return c.Name, nil
+			}
+		}
+		return "", fmt.Errorf("%w: %s", ErrContextNotFound, k.opts.Context)
+	case k.opts.AWS.Cluster != "":
+		return aws.CreateKubeContext()
+	case k.opts.NonInteractive:
+		return "", fmt.Errorf("%w: use --context or --cluster", console.ErrNonInteractive)
+	}
+
+	for {
+		labels := make([]string, len(contexts))
+		byLabel := map[string]kubeconfig.Context{}
+		for i, c := range contexts {
+			if c.Name != "" {
+				labels[i] = c.Label()
+				byLabel[labels[i]] = c
+			}
+		}
+
+		selected, err := k.executor.SelectValueFromList(labels, "Kube Context", aws.CreateKubeContext)
+		if err != nil {
+			return "", err
+		}
+		c, ok := byLabel[selected]
+		if !ok {
+			// created for the selected profile
+			return selected, nil
+		}
+		ok, err = k.confirmAccount(c, account, region)
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			return c.Name, nil
+		}
+
+		contexts = inAccount(contexts, account)
+		k.executor.Warn(fmt.Sprintf("showing only the kube contexts of account %s", account))
+	}
 }
@@ -5,8 +5,12 @@ package kubectl
 
 import (
 	"errors"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/eiladin/ekalias/aws"
+	"github.com/eiladin/ekalias/console"
 	"github.com/eiladin/ekalias/mocks"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
@@ -20,6 +24,14 @@ func TestKubectlSuite(t *testing.T) {
 	suite.Run(t, new(KubectlSuite))
 }
 
+func (suite KubectlSuite) SetupTest() {
+	os.Setenv("KUBECONFIG", filepath.Join("testdata", "missing"))
+}
+
+func (suite KubectlSuite) TearDownTest() {
+	os.Unsetenv("KUBECONFIG")
+}
+
 func (suite KubectlSuite) TestNew() {
 	e := new(mocks.Executor)
 	k := New(e)
@@ -57,7 +69,7 @@ func (suite KubectlSuite) TestFindContexts() {
 	for _, c := range cases {
 		e := new(mocks.Executor)
 		e.On("FindExecutable", executable).Return(c.findExecutableResult, c.findExecutableError)
-		e.On("ExecCommand", executable, "config", "get-contexts", "-o", "name").Return(c.execCommandResult, c.execCommandError)
+		e.On("ExecCommand", mock.Anything, executable, "config", "get-contexts", "-o", "name").Return(c.execCommandResult, c.execCommandError)
 		k := New(e)
 
 		res, err := k.findContexts()
@@ -73,7 +85,7 @@ func (suite KubectlSuite) TestFindContexts() {
 func (suite KubectlSuite) TestSelectContext() {
 	e := new(mocks.Executor)
 	e.On("FindExecutable", executable).Return(executable, nil)
-	e.On("ExecCommand", executable, "config", "get-contexts", "-o", "name").Return("a\nb\nc", nil)
+	e.On("ExecCommand", mock.Anything, executable, "config", "get-contexts", "-o", "name").Return("a\nb\nc", nil)
 	e.On("ReadInput").Return("2", nil)
 	e.On("SelectValueFromList", []string{"a", "b", "c"}, "Kube Context", mock.Anything).Return("b", nil)
 	k := New(e)
@@ -112,3 +124,56 @@ func (suite KubectlSuite) TestFindCli() {
 		}
 	}
 }
+
+func (suite KubectlSuite) TestSelectContextWithOptions() {
+	cases := []struct {
+		opts           Options
+		expectedResult string
+		expectedError  error
+	}{
+		{opts: Options{Context: "b"}, expectedResult: "b"},
+		{opts: Options{Context: "d"}, expectedError: ErrContextNotFound},
+		{opts: Options{NonInteractive: true}, expectedError: console.ErrNonInteractive},
+		{opts: Options{AWS: aws.Options{Region: "us-east-1", Cluster: "x", KubeAlias: "x"}}, expectedResult: "x"},
+	}
+
+	for _, c := range cases {
+		e := new(mocks.Executor)
+		e.On("FindExecutable", executable).Return(executable, nil)
+		e.On("FindExecutable", "aws").Return("aws", nil)
+		e.On("ExecCommand", mock.Anything, executable, "config", "get-contexts", "-o", "name").Return("a\nb\nc", nil)
+		e.On("ExecCommand", mock.Anything, "aws", "eks", "list-clusters", "--region", "us-east-1").Return(`{"clusters":["x"]}`, nil)
+		e.On("ExecCommand", mock.Anything, "aws", "eks", "update-kubeconfig", "--region", "us-east-1", "--name", "x", "--alias", "x").Return("Updated context x in /home/user/.kube/config", nil)
+		k := New(e).WithOptions(c.opts)
+
+		res, err := k.SelectContext()
+		if c.expectedError != nil {
+			suite.True(errors.Is(err, c.expectedError))
+		} else {
+			suite.NoError(err)
+		}
+		suite.Equal(c.expectedResult, res)
+		e.AssertNotCalled(suite.T(), "SelectValueFromList", mock.Anything, mock.Anything, mock.Anything)
+	}
+}
+
+func (suite KubectlSuite) TestSelectContextFromKubeconfig() {
+	os.Setenv("KUBECONFIG", filepath.Join("testdata", "config"))
+
+	e := new(mocks.Executor)
+	e.On("SelectValueFromList", []string{"prod → arn:aws:eks:us-east-1:123456789012:cluster/prod (payments)", "kind-local"}, "Kube Context", mock.Anything).Return("prod → arn:aws:eks:us-east-1:123456789012:cluster/prod (payments)", nil)
+	k := New(e)
+
+	res, err := k.SelectContext()
+	suite.NoError(err)
+	suite.Equal("prod", res)
+	e.AssertNotCalled(suite.T(), "FindExecutable", executable)
+
+	e = new(mocks.Executor)
+	e.On("SelectValueFromList", mock.Anything, "Kube Context", mock.Anything).Return("new-context", nil)
+	k = New(e)
+
+	res, err = k.SelectContext()
+	suite.NoError(err)
+	suite.Equal("new-context", res)
+}
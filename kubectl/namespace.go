@@ -0,0 +1,76 @@
+package kubectl
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var ErrInvalidNamespace = errors.New("invalid namespace")
+
+// keepNamespace is the picker entry that leaves the context's namespace alone.
+const keepNamespace = "(keep the context's namespace)"
+
+var namespacePattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+func ValidateNamespace(ns string) error {
+	if len(ns) > 63 || !namespacePattern.MatchString(ns) {
+		return fmt.Errorf("%w: %q", ErrInvalidNamespace, ns)
+	}
+	return nil
+}
+
+func (k Kubectl) findNamespaces(context string) ([]string, error) {
+	kubectl, err := k.FindCli()
+	if err != nil {
+		return nil, err
+	}
+	e := k.executor
+	if k.opts.AWS.Profile != "" {
+		// the context's credential plugin may rely on AWS_PROFILE
+		e = e.WithEnv("AWS_PROFILE=" + k.opts.AWS.Profile)
+	}
+	out, err := e.ExecCommand(k.ctx, kubectl, "get", "namespaces", "-o", "name", "--context", context)
+	if err != nil {
+		return nil, err
+	}
+
+	var namespaces []string
+	for _, line := range strings.Split(out, "\n") {
+		if ns := strings.TrimPrefix(strings.TrimSpace(line), "namespace/"); ns != "" {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	return namespaces, nil
+}
+
+// SelectNamespace picks the namespace the alias switches to in context. This
+// step is optional: an empty result keeps the namespace set in the context,
+// which is also what happens when the namespaces cannot be listed.
+func (k Kubectl) SelectNamespace(context string) (string, error) {
+	switch {
+	case k.opts.Namespace != "":
+		if err := ValidateNamespace(k.opts.Namespace); err != nil {
+			return "", err
+		}
+		return k.opts.Namespace, nil
+	case k.opts.NonInteractive:
+		return "", nil
+	}
+
+	namespaces, err := k.findNamespaces(context)
+	if err != nil {
+		k.executor.Warn(fmt.Sprintf("unable to list namespaces in %s, keeping the context's namespace: %s", context, err.Error()))
+		return "", nil
+	}
+	if len(namespaces) == 0 {
+		return "", nil
+	}
+
+	selected, err := k.executor.SelectValueFromList(append([]string{keepNamespace}, namespaces...), "Namespace", nil)
+	if err != nil || selected == keepNamespace {
+		return "", err
+	}
+	return selected, nil
+}
@@ -0,0 +1,116 @@
+//go:build test
+// +build test
+
+package kubectl
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/eiladin/ekalias/aws"
+	"github.com/eiladin/ekalias/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type NamespaceSuite struct {
+	suite.Suite
+}
+
+func TestNamespaceSuite(t *testing.T) {
+	suite.Run(t, new(NamespaceSuite))
+}
+
+func (suite NamespaceSuite) TestValidateNamespace() {
+	for _, ns := range []string{"default", "kube-system", "team-1", "a"} {
+		suite.NoError(ValidateNamespace(ns), ns)
+	}
+	for _, ns := range []string{"", "Default", "-team", "team-", "team_1", "team 1", "$(id)", string(make([]byte, 64))} {
+		suite.True(errors.Is(ValidateNamespace(ns), ErrInvalidNamespace), ns)
+	}
+}
+
+func (suite NamespaceSuite) TestSelectNamespace() {
+	cases := []struct {
+		listResult    string
+		listError     error
+		selected      string
+		expected      string
+		expectedError bool
+		labels        []string
+	}{
+		{listResult: "namespace/default\nnamespace/payments\n", selected: "payments", expected: "payments", labels: []string{keepNamespace, "default", "payments"}},
+		{listResult: "namespace/default\nnamespace/payments\n", selected: keepNamespace, expected: "", labels: []string{keepNamespace, "default", "payments"}},
+		{listResult: "namespace/default\n", selected: "", expectedError: true, labels: []string{keepNamespace, "default"}},
+		{listResult: "", expected: ""},
+		{listError: errors.New("connection refused"), expected: ""},
+	}
+
+	for _, c := range cases {
+		e := new(mocks.Executor)
+		e.On("FindExecutable", executable).Return(executable, nil)
+		e.On("ExecCommand", mock.Anything, executable, "get", "namespaces", "-o", "name", "--context", "prod").Return(c.listResult, c.listError)
+		e.On("Warn", mock.Anything).Return()
+		var selectErr error
+		if c.expectedError {
+			selectErr = errors.New("select")
+		}
+		e.On("SelectValueFromList", mock.Anything, "Namespace", mock.Anything).Return(c.selected, selectErr)
+
+		res, err := New(e).SelectNamespace("prod")
+		if c.expectedError {
+			suite.Error(err)
+		} else {
+			suite.NoError(err)
+		}
+		suite.Equal(c.expected, res)
+
+		if c.labels != nil {
+			e.AssertCalled(suite.T(), "SelectValueFromList", c.labels, "Namespace", mock.Anything)
+		} else {
+			e.AssertNotCalled(suite.T(), "SelectValueFromList", mock.Anything, mock.Anything, mock.Anything)
+		}
+		if c.listError != nil {
+			e.AssertCalled(suite.T(), "Warn", "unable to list namespaces in prod, keeping the context's namespace: connection refused")
+		}
+	}
+}
+
+func (suite NamespaceSuite) TestSelectNamespaceWithProfile() {
+	e := new(mocks.Executor)
+	pe := new(mocks.Executor)
+	e.On("FindExecutable", executable).Return(executable, nil)
+	e.On("WithEnv", "AWS_PROFILE=prod-admin").Return(pe)
+	pe.On("ExecCommand", mock.Anything, executable, "get", "namespaces", "-o", "name", "--context", "prod").Return("namespace/payments\n", nil)
+	e.On("SelectValueFromList", mock.Anything, "Namespace", mock.Anything).Return("payments", nil)
+
+	res, err := New(e).WithOptions(Options{AWS: aws.Options{Profile: "prod-admin"}}).SelectNamespace("prod")
+	suite.NoError(err)
+	suite.Equal("payments", res)
+	e.AssertNotCalled(suite.T(), "ExecCommand", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite NamespaceSuite) TestSelectNamespaceWithOptions() {
+	cases := []struct {
+		opts          Options
+		expected      string
+		expectedError error
+	}{
+		{opts: Options{Namespace: "payments"}, expected: "payments"},
+		{opts: Options{Namespace: "Payments"}, expectedError: ErrInvalidNamespace},
+		{opts: Options{NonInteractive: true}, expected: ""},
+		{opts: Options{Namespace: "payments", NonInteractive: true}, expected: "payments"},
+	}
+
+	for _, c := range cases {
+		e := new(mocks.Executor)
+		res, err := New(e).WithOptions(c.opts).SelectNamespace("prod")
+		if c.expectedError != nil {
+			suite.True(errors.Is(err, c.expectedError))
+		} else {
+			suite.NoError(err)
+		}
+		suite.Equal(c.expected, res)
+		e.AssertNotCalled(suite.T(), "ExecCommand", mock.Anything, mock.Anything, mock.Anything)
+	}
+}
@@ -9,5 +9,5 @@ import (
 var version = "dev"
 
 func main() {
-	cmd.Execute(version, os.Args[1:])
+	os.Exit(cmd.Execute(version, os.Args[1:]))
 }
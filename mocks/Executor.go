@@ -5,34 +5,39 @@
 
 package mocks
 
-import mock "github.com/stretchr/testify/mock"
+import (
+	context "context"
+
+	console "github.com/eiladin/ekalias/console"
+	mock "github.com/stretchr/testify/mock"
+)
 
 // Executor is an autogenerated mock type for the Executor type
 type Executor struct {
 	mock.Mock
 }
 
-// ExecCommand provides a mock function with given fields: _a0, _a1
-func (_m *Executor) ExecCommand(_a0 string, _a1 ...string) (string, error) {
-	_va := make([]interface{}, len(_a1))
-	for _i := range _a1 {
-		_va[_i] = _a1[_i]
+// ExecCommand provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Executor) ExecCommand(_a0 context.Context, _a1 string, _a2 ...string) (string, error) {
+	_va := make([]interface{}, len(_a2))
+	for _i := range _a2 {
+		_va[_i] = _a2[_i]
 	}
 	var _ca []interface{}
-	_ca = append(_ca, _a0)
+	_ca = append(_ca, _a0, _a1)
 	_ca = append(_ca, _va...)
 	ret := _m.Called(_ca...)
 
 	var r0 string
-	if rf, ok := ret.Get(0).(func(string, ...string) string); ok {
-		r0 = rf(_a0, _a1...)
+	if rf, ok := ret.Get(0).(func(context.Context, string, ...string) string); ok {
+		r0 = rf(_a0, _a1, _a2...)
 	} else {
 		r0 = ret.Get(0).(string)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string, ...string) error); ok {
-		r1 = rf(_a0, _a1...)
+	if rf, ok := ret.Get(1).(func(context.Context, string, ...string) error); ok {
+		r1 = rf(_a0, _a1, _a2...)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -40,20 +45,20 @@ func (_m *Executor) ExecCommand(_a0 string, _a1 ...string) (string, error) {
 	return r0, r1
 }
 
-// ExecInteractive provides a mock function with given fields: _a0, _a1
-func (_m *Executor) ExecInteractive(_a0 string, _a1 ...string) error {
-	_va := make([]interface{}, len(_a1))
-	for _i := range _a1 {
-		_va[_i] = _a1[_i]
+// ExecInteractive provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Executor) ExecInteractive(_a0 context.Context, _a1 string, _a2 ...string) error {
+	_va := make([]interface{}, len(_a2))
+	for _i := range _a2 {
+		_va[_i] = _a2[_i]
 	}
 	var _ca []interface{}
-	_ca = append(_ca, _a0)
+	_ca = append(_ca, _a0, _a1)
 	_ca = append(_ca, _va...)
 	ret := _m.Called(_ca...)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string, ...string) error); ok {
-		r0 = rf(_a0, _a1...)
+	if rf, ok := ret.Get(0).(func(context.Context, string, ...string) error); ok {
+		r0 = rf(_a0, _a1, _a2...)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -144,3 +149,30 @@ func (_m *Executor) SelectValueFromList(_a0 []string, _a1 string, _a2 func() (st
 
 	return r0, r1
 }
+
+// Warn provides a mock function with given fields: _a0
+func (_m *Executor) Warn(_a0 string) {
+	_m.Called(_a0)
+}
+
+// WithEnv provides a mock function with given fields: _a0
+func (_m *Executor) WithEnv(_a0 ...string) console.Executor {
+	_va := make([]interface{}, len(_a0))
+	for _i := range _a0 {
+		_va[_i] = _a0[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 console.Executor
+	if rf, ok := ret.Get(0).(func(...string) console.Executor); ok {
+		r0 = rf(_a0...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(console.Executor)
+		}
+	}
+
+	return r0
+}
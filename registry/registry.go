@@ -0,0 +1,150 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+var ErrNotFound = errors.New("alias not found")
+var ErrExists = errors.New("alias already exists")
+
+type Alias struct {
+	Name       string    `yaml:"name"`
+	Profile    string    `yaml:"profile"`
+	Context    string    `yaml:"context"`
+	Namespace  string    `yaml:"namespace,omitempty"`
+	Kubeconfig string    `yaml:"kubeconfig,omitempty"`
+	Region     string    `yaml:"region,omitempty"`
+	ClusterARN string    `yaml:"clusterArn,omitempty"`
+	Shell      string    `yaml:"shell,omitempty"`
+	RcFile     string    `yaml:"rcFile,omitempty"`
+	CreatedAt  time.Time `yaml:"createdAt"`
+}
+
+type Registry struct {
+	path    string
+	Aliases []Alias `yaml:"aliases"`
+}
+
+// DefaultPath returns $XDG_CONFIG_HOME/ekalias/aliases.yaml, using
+// ~/.config when XDG_CONFIG_HOME is not set.
+func DefaultPath() (string, error) {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "ekalias", "aliases.yaml"), nil
+}
+
+// Load reads the registry at path. A missing file is an empty registry.
+func Load(path string) (*Registry, error) {
+	r := &Registry{path: path}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, r); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return r, nil
+}
+
+func (r *Registry) Save() error {
+	sort.Slice(r.Aliases, func(i, j int) bool { return r.Aliases[i].Name < r.Aliases[j].Name })
+	data, err := yaml.Marshal(r)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(r.path, data, 0644)
+}
+
+func (r *Registry) index(name string) int {
+	for i, a := range r.Aliases {
+		if a.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func (r *Registry) Get(name string) (Alias, error) {
+	i := r.index(name)
+	if i < 0 {
+		return Alias{}, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return r.Aliases[i], nil
+}
+
+// Put adds a, replacing any alias with the same name.
+func (r *Registry) Put(a Alias) {
+	if i := r.index(a.Name); i >= 0 {
+		r.Aliases[i] = a
+		return
+	}
+	r.Aliases = append(r.Aliases, a)
+}
+
+func (r *Registry) Remove(name string) error {
+	i := r.index(name)
+	if i < 0 {
+		return fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	r.Aliases = append(r.Aliases[:i], r.Aliases[i+1:]...)
+	return nil
+}
+
+func (r *Registry) Rename(oldName, newName string) error {
+	i := r.index(oldName)
+	if i < 0 {
+		return fmt.Errorf("%w: %s", ErrNotFound, oldName)
+	}
+	if r.index(newName) >= 0 {
+		return fmt.Errorf("%w: %s", ErrExists, newName)
+	}
+	r.Aliases[i].Name = newName
+	return nil
+}
+
+// KubeconfigPath returns where the isolated kubeconfig of an alias is kept,
+// in a kube directory next to the registry file.
+func (r *Registry) KubeconfigPath(name string) string {
+	return filepath.Join(filepath.Dir(r.path), "kube", name+".yaml")
+}
+
+// Orphans returns the isolated kubeconfig files that no alias uses anymore.
+func (r *Registry) Orphans() ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(filepath.Dir(r.path), "kube", "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	used := map[string]bool{}
+	for _, a := range r.Aliases {
+		if a.Kubeconfig != "" {
+			used[a.Kubeconfig] = true
+		}
+	}
+	var orphans []string
+	for _, f := range files {
+		if !used[f] {
+			orphans = append(orphans, f)
+		}
+	}
+	return orphans, nil
+}
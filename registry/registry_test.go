@@ -0,0 +1,125 @@
+package registry
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type RegistrySuite struct {
+	suite.Suite
+	dir string
+}
+
+func TestRegistrySuite(t *testing.T) {
+	suite.Run(t, new(RegistrySuite))
+}
+
+func (suite *RegistrySuite) SetupTest() {
+	dir, err := ioutil.TempDir("", "ekalias")
+	suite.Require().NoError(err)
+	suite.dir = dir
+}
+
+func (suite *RegistrySuite) TearDownTest() {
+	os.RemoveAll(suite.dir)
+}
+
+func (suite *RegistrySuite) TestDefaultPath() {
+	os.Setenv("XDG_CONFIG_HOME", "/tmp/config")
+	defer os.Unsetenv("XDG_CONFIG_HOME")
+
+	res, err := DefaultPath()
+	suite.NoError(err)
+	suite.Equal("/tmp/config/ekalias/aliases.yaml", res)
+}
+
+func (suite *RegistrySuite) TestLoadMissing() {
+	r, err := Load(filepath.Join(suite.dir, "aliases.yaml"))
+	suite.NoError(err)
+	suite.Empty(r.Aliases)
+}
+
+func (suite *RegistrySuite) TestLoadInvalid() {
+	path := filepath.Join(suite.dir, "aliases.yaml")
+	suite.Require().NoError(ioutil.WriteFile(path, []byte("aliases: {"), 0644))
+
+	_, err := Load(path)
+	suite.Error(err)
+}
+
+func (suite *RegistrySuite) TestSaveAndLoad() {
+	path := filepath.Join(suite.dir, "ekalias", "aliases.yaml")
+	created := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	r, err := Load(path)
+	suite.Require().NoError(err)
+	r.Put(Alias{Name: "prod", Profile: "prod-admin", Context: "arn:aws:eks:us-east-1:123456789012:cluster/prod", Region: "us-east-1", ClusterARN: "arn:aws:eks:us-east-1:123456789012:cluster/prod", CreatedAt: created})
+	r.Put(Alias{Name: "dev", Profile: "dev", Context: "dev", CreatedAt: created})
+	suite.NoError(r.Save())
+
+	r, err = Load(path)
+	suite.Require().NoError(err)
+	suite.Len(r.Aliases, 2)
+	suite.Equal("dev", r.Aliases[0].Name)
+
+	a, err := r.Get("prod")
+	suite.NoError(err)
+	suite.Equal("us-east-1", a.Region)
+	suite.Equal(created, a.CreatedAt)
+}
+
+func (suite *RegistrySuite) TestPutReplaces() {
+	r := &Registry{}
+	r.Put(Alias{Name: "dev", Profile: "a"})
+	r.Put(Alias{Name: "dev", Profile: "b"})
+	suite.Len(r.Aliases, 1)
+	suite.Equal("b", r.Aliases[0].Profile)
+}
+
+func (suite *RegistrySuite) TestGetMissing() {
+	r := &Registry{}
+	_, err := r.Get("dev")
+	suite.True(errors.Is(err, ErrNotFound))
+}
+
+func (suite *RegistrySuite) TestRemove() {
+	r := &Registry{Aliases: []Alias{{Name: "a"}, {Name: "b"}, {Name: "c"}}}
+	suite.NoError(r.Remove("b"))
+	suite.Equal([]Alias{{Name: "a"}, {Name: "c"}}, r.Aliases)
+	suite.True(errors.Is(r.Remove("b"), ErrNotFound))
+}
+
+func (suite *RegistrySuite) TestRename() {
+	r := &Registry{Aliases: []Alias{{Name: "a"}, {Name: "b"}}}
+	suite.NoError(r.Rename("a", "c"))
+	suite.Equal("c", r.Aliases[0].Name)
+	suite.True(errors.Is(r.Rename("a", "d"), ErrNotFound))
+	suite.True(errors.Is(r.Rename("b", "c"), ErrExists))
+}
+
+func (suite *RegistrySuite) TestKubeconfigPathAndOrphans() {
+	r, err := Load(filepath.Join(suite.dir, "aliases.yaml"))
+	suite.Require().NoError(err)
+	suite.Equal(filepath.Join(suite.dir, "kube", "prod.yaml"), r.KubeconfigPath("prod"))
+
+	orphans, err := r.Orphans()
+	suite.NoError(err)
+	suite.Empty(orphans)
+
+	suite.Require().NoError(os.MkdirAll(filepath.Join(suite.dir, "kube"), 0700))
+	for _, name := range []string{"prod", "old"} {
+		suite.Require().NoError(ioutil.WriteFile(r.KubeconfigPath(name), nil, 0600))
+	}
+	r.Put(Alias{Name: "prod", Kubeconfig: r.KubeconfigPath("prod")})
+	r.Put(Alias{Name: "dev"})
+
+	orphans, err = r.Orphans()
+	suite.NoError(err)
+	suite.Equal([]string{r.KubeconfigPath("old")}, orphans)
+}
@@ -0,0 +1,65 @@
+package shell
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+var ErrInvalidName = errors.New("invalid alias name")
+var ErrReservedName = errors.New("alias name is a shell keyword or builtin")
+
+var (
+	invalidNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+	posixName        = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]*$`)
+	identifierName   = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+)
+
+// reserved lists keywords and builtins of the supported shells that an
+// alias must not shadow.
+var reserved = map[string]bool{
+	"alias": true, "begin": true, "case": true, "cd": true, "def": true, "do": true, "done": true,
+	"echo": true, "elif": true, "else": true, "end": true, "esac": true, "eval": true, "exec": true,
+	"exit": true, "export": true, "false": true, "fi": true, "for": true, "foreach": true,
+	"function": true, "if": true, "in": true, "let": true, "return": true, "select": true,
+	"set": true, "source": true, "switch": true, "test": true, "then": true, "time": true,
+	"true": true, "unalias": true, "until": true, "while": true,
+}
+
+func validateName(name, shell string, pattern *regexp.Regexp) error {
+	if !pattern.MatchString(name) {
+		return fmt.Errorf("%w for %s: %q (use letters, digits, '_' and '-')", ErrInvalidName, shell, name)
+	}
+	if reserved[name] {
+		return fmt.Errorf("%w: %s", ErrReservedName, name)
+	}
+	return nil
+}
+
+// NewNamer parses a name template. Anything in the result that is not valid
+// in a profile or alias name, such as the spaces in account names, is
+// replaced with "-".
+func NewNamer(kind, text string) (func(data interface{}) (string, error), error) {
+	tmpl, err := template.New(kind).Funcs(template.FuncMap{
+		"lower": strings.ToLower,
+		"upper": strings.ToUpper,
+	}).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(data interface{}) (string, error) {
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return "", err
+		}
+		name := strings.Trim(invalidNameChars.ReplaceAllString(buf.String(), "-"), "-")
+		if name == "" {
+			return "", fmt.Errorf("%s name template %q produced an empty name", kind, text)
+		}
+		return name, nil
+	}, nil
+}
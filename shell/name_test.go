@@ -0,0 +1,95 @@
+package shell
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type NameSuite struct {
+	suite.Suite
+}
+
+func TestNameSuite(t *testing.T) {
+	suite.Run(t, new(NameSuite))
+}
+
+func (suite NameSuite) TestValidate() {
+	cases := []struct {
+		shell   string
+		valid   []string
+		invalid []string
+	}{
+		{
+			shell:   "bash",
+			valid:   []string{"prod", "prod-us-east-1", "k8s.dev", "_dev", "1prod"},
+			invalid: []string{"", "my prod", "prod'", `prod"`, "prod;rm", "$(id)", "`id`", "-prod", "a/b", "a=b", "prod|cat"},
+		},
+		{
+			shell:   "fish",
+			valid:   []string{"prod", "prod-us-east-1", "k8s.dev"},
+			invalid: []string{"my prod", "-prod", "a/b", "prod;rm"},
+		},
+		{
+			shell:   "pwsh",
+			valid:   []string{"prod", "Prod-Admin", "_dev"},
+			invalid: []string{"1prod", "k8s.dev", "my prod", "$prod", "prod;rm"},
+		},
+		{
+			shell:   "nu",
+			valid:   []string{"prod", "prod-us-east-1"},
+			invalid: []string{"1prod", "k8s.dev", "my prod", "prod;rm"},
+		},
+	}
+
+	for _, c := range cases {
+		r, err := NewRenderer(c.shell)
+		suite.Require().NoError(err)
+		for _, name := range c.valid {
+			suite.NoError(r.Validate(name), "%s %q", c.shell, name)
+		}
+		for _, name := range c.invalid {
+			suite.True(errors.Is(r.Validate(name), ErrInvalidName), "%s %q", c.shell, name)
+		}
+	}
+
+	for _, name := range []string{"cd", "if", "function", "def"} {
+		suite.True(errors.Is(Posix{}.Validate(name), ErrReservedName), name)
+	}
+}
+
+func (suite NameSuite) TestNewNamer() {
+	data := struct {
+		Profile string
+		Cluster string
+	}{Profile: "Prod Admin", Cluster: "main/cluster"}
+
+	cases := []struct {
+		template string
+		expected string
+		err      bool
+	}{
+		{template: "{{.Profile}}-{{.Cluster}}", expected: "Prod-Admin-main-cluster"},
+		{template: "{{.Profile | lower}}", expected: "prod-admin"},
+		{template: "{{.Cluster | upper}}", expected: "MAIN-CLUSTER"},
+		{template: "'{{.Profile}}'", expected: "Prod-Admin"},
+		{template: " ", err: true},
+		{template: "{{.Region}}", err: true},
+	}
+
+	for _, c := range cases {
+		name, err := NewNamer("alias", c.template)
+		suite.Require().NoError(err, c.template)
+		res, err := name(data)
+		if c.err {
+			suite.Error(err, c.template)
+			continue
+		}
+		suite.NoError(err, c.template)
+		suite.Equal(c.expected, res, c.template)
+	}
+
+	_, err := NewNamer("alias", "{{.Profile")
+	suite.Error(err)
+}
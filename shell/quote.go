@@ -0,0 +1,55 @@
+package shell
+
+import (
+	"regexp"
+	"strings"
+)
+
+// safeWord matches values that mean the same thing quoted or not in every
+// supported shell, they are left bare to keep the aliases readable.
+var safeWord = regexp.MustCompile(`^[A-Za-z0-9@+=:,./_-]+$`)
+
+// posixQuote quotes s as a single word for sh, bash and zsh.
+func posixQuote(s string) string {
+	if safeWord.MatchString(s) {
+		return s
+	}
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
+
+// posixAliasBody quotes the body of an alias definition. The body is parsed
+// again when the alias is used, so its values are quoted by the caller too.
+func posixAliasBody(s string) string {
+	if !strings.ContainsAny(s, "$`\"\\!") {
+		return `"` + s + `"`
+	}
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
+
+// fishQuote quotes s as a single word for fish, where \ and ' are the only
+// characters special inside single quotes.
+func fishQuote(s string) string {
+	if safeWord.MatchString(s) {
+		return s
+	}
+	r := strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+	return "'" + r.Replace(s) + "'"
+}
+
+// powerShellQuote quotes s as a PowerShell string. Double quoted strings
+// expand $ and `, so anything but plain values is single quoted, doubling
+// every character PowerShell accepts as a single quote.
+func powerShellQuote(s string) string {
+	if safeWord.MatchString(s) {
+		return `"` + s + `"`
+	}
+	r := strings.NewReplacer("'", "''", "‘", "‘‘", "’", "’’", "‚", "‚‚", "‛", "‛‛")
+	return "'" + r.Replace(s) + "'"
+}
+
+// nushellQuote quotes s as a Nushell double quoted string, which does not
+// interpolate but does process backslash escapes.
+func nushellQuote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+	return `"` + r.Replace(s) + `"`
+}
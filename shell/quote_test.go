@@ -0,0 +1,114 @@
+package shell
+
+import (
+	"math/rand"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/quick"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type QuoteSuite struct {
+	suite.Suite
+}
+
+func TestQuoteSuite(t *testing.T) {
+	suite.Run(t, new(QuoteSuite))
+}
+
+// hostile values are generated from characters that are special to at least
+// one of the supported shells.
+type hostile string
+
+const hostileChars = "abcXYZ019-_./:@=+,%~ \t\n$`\"'\\!;&|()<>{}[]*?#^‘’é"
+
+func (hostile) Generate(r *rand.Rand, size int) reflect.Value {
+	chars := []rune(hostileChars)
+	b := make([]rune, r.Intn(size+1))
+	for i := range b {
+		b[i] = chars[r.Intn(len(chars))]
+	}
+	return reflect.ValueOf(hostile(b))
+}
+
+// runPosix sources the alias in sh and runs it with a kubectl stub, returning
+// the context kubectl received and the exported AWS_PROFILE.
+func runPosix(sh string, args []string, a Alias) (string, error) {
+	script := strings.Join([]string{
+		`kubectl() { printf 'kubectl'; printf ' [%s]' "$@"; printf '\n'; }`,
+		Posix{}.Render(a),
+		a.Name,
+		`printf 'profile=%s\n' "$AWS_PROFILE"`,
+	}, "\n")
+	out, err := exec.Command(sh, append(args, "-c", script)...).CombinedOutput()
+	return string(out), err
+}
+
+func (suite QuoteSuite) TestPosixRoundTrip() {
+	shells := map[string][]string{"sh": nil, "bash": {"-O", "expand_aliases"}}
+	for sh, args := range shells {
+		if _, err := exec.LookPath(sh); err != nil {
+			continue
+		}
+
+		f := func(profile, context, namespace hostile) bool {
+			out, err := runPosix(sh, args, Alias{Name: "ekalias_test", Profile: string(profile), Context: string(context), Namespace: string(namespace)})
+			expected := "kubectl [config] [use-context] [" + string(context) + "]\n"
+			if namespace != "" {
+				expected += "kubectl [config] [set-context] [--current] [--namespace=" + string(namespace) + "]\n"
+			}
+			expected += "profile=" + string(profile) + "\n"
+			if err != nil || out != expected {
+				suite.T().Logf("%s: profile %q context %q\nexpected %q\ngot      %q (%v)", sh, profile, context, expected, out, err)
+				return false
+			}
+			return true
+		}
+		suite.NoError(quick.Check(f, &quick.Config{MaxCount: 200}), sh)
+	}
+}
+
+func (suite QuoteSuite) TestPosixInjection() {
+	for _, v := range []string{"$(echo pwned)", "`echo pwned`", `"; echo pwned; "`, `'; echo pwned; '`, "x && echo pwned", "${HOME}", "!!", `\`} {
+		out, err := runPosix("sh", nil, Alias{Name: "ekalias_test", Profile: v, Context: v})
+		suite.NoError(err, v)
+		suite.Equal("kubectl [config] [use-context] ["+v+"]\nprofile="+v+"\n", out, v)
+	}
+}
+
+func (suite QuoteSuite) TestQuote() {
+	cases := []struct {
+		value, posix, fish, pwsh, nu string
+	}{
+		{
+			value: "prod-admin",
+			posix: "prod-admin", fish: "prod-admin", pwsh: `"prod-admin"`, nu: `"prod-admin"`,
+		},
+		{
+			value: "",
+			posix: "''", fish: "''", pwsh: "''", nu: `""`,
+		},
+		{
+			value: "it's $HOME",
+			posix: `'it'\''s $HOME'`, fish: `'it\'s $HOME'`, pwsh: `'it''s $HOME'`, nu: `"it's $HOME"`,
+		},
+		{
+			value: "a\\b\"c`d’e",
+			posix: "'a\\b\"c`d’e'", fish: "'a\\\\b\"c`d’e'", pwsh: "'a\\b\"c`d’’e'", nu: "\"a\\\\b\\\"c`d’e\"",
+		},
+		{
+			value: "line\nbreak",
+			posix: "'line\nbreak'", fish: "'line\nbreak'", pwsh: "'line\nbreak'", nu: `"line\nbreak"`,
+		},
+	}
+
+	for _, c := range cases {
+		suite.Equal(c.posix, posixQuote(c.value), c.value)
+		suite.Equal(c.fish, fishQuote(c.value), c.value)
+		suite.Equal(c.pwsh, powerShellQuote(c.value), c.value)
+		suite.Equal(c.nu, nushellQuote(c.value), c.value)
+	}
+}
@@ -0,0 +1,280 @@
+package shell
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+const (
+	blockBegin  = "# >>> ekalias >>>"
+	blockEnd    = "# <<< ekalias <<<"
+	entryPrefix = "# ekalias: "
+	backupExt   = ".ekalias.bak"
+)
+
+var ErrUnsupportedShell = errors.New("unsupported shell")
+var ErrMalformedBlock = errors.New("ekalias block is missing its end marker")
+
+// Detect returns the name of the shell found in the given $SHELL value,
+// falling back to the platform default when it is empty.
+func Detect(shellEnv string) string {
+	if shellEnv == "" {
+		if runtime.GOOS == "windows" {
+			return "powershell"
+		}
+		return "bash"
+	}
+	return strings.TrimSuffix(filepath.Base(shellEnv), ".exe")
+}
+
+// RcFile returns the startup file ekalias writes aliases to for the given shell.
+func RcFile(shell, home string) (string, error) {
+	switch shell {
+	case "bash":
+		return filepath.Join(home, ".bashrc"), nil
+	case "zsh":
+		if zdotdir := os.Getenv("ZDOTDIR"); zdotdir != "" {
+			return filepath.Join(zdotdir, ".zshrc"), nil
+		}
+		return filepath.Join(home, ".zshrc"), nil
+	case "sh", "dash":
+		return envFile(home, ".profile"), nil
+	case "ksh":
+		return envFile(home, ".kshrc"), nil
+	case "mksh":
+		return envFile(home, ".mkshrc"), nil
+	case "fish":
+		return filepath.Join(configDir(home), "fish", "config.fish"), nil
+	case "pwsh", "powershell":
+		if runtime.GOOS == "windows" {
+			return filepath.Join(home, "Documents", "PowerShell", "Microsoft.PowerShell_profile.ps1"), nil
+		}
+		return filepath.Join(configDir(home), "powershell", "Microsoft.PowerShell_profile.ps1"), nil
+	case "nu":
+		return filepath.Join(configDir(home), "nushell", "config.nu"), nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedShell, shell)
+	}
+}
+
+// envFile returns the file $ENV names, which interactive posix shells read
+// on startup, or name in home when it is not set.
+func envFile(home, name string) string {
+	if env := os.ExpandEnv(os.Getenv("ENV")); env != "" {
+		return env
+	}
+	return filepath.Join(home, name)
+}
+
+func configDir(home string) string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return dir
+	}
+	return filepath.Join(home, ".config")
+}
+
+// Definition is the rendered alias installed under Name.
+type Definition struct {
+	Name string
+	Text string
+}
+
+// Install writes definition into the ekalias managed block of the file at
+// path, replacing any existing entry with the same name. The file is backed
+// up before it is changed.
+func Install(path, name, definition string) error {
+	return InstallAll(path, []Definition{{Name: name, Text: definition}})
+}
+
+// InstallAll writes every definition into the ekalias managed block of the
+// file at path like Install does. The file is written, and backed up, once.
+func InstallAll(path string, definitions []Definition) error {
+	content, err := readFile(path)
+	if err != nil {
+		return err
+	}
+
+	b, err := parseBlock(content)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	for _, d := range definitions {
+		b.set(d.Name, strings.Split(strings.TrimRight(d.Text, "\n"), "\n"))
+	}
+
+	return writeFile(path, content, b.String())
+}
+
+// Uninstall removes the entries with the given names from the ekalias
+// managed block of the file at path, dropping the block once it is empty.
+func Uninstall(path string, names ...string) error {
+	content, err := readFile(path)
+	if err != nil {
+		return err
+	}
+
+	b, err := parseBlock(content)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	for _, name := range names {
+		b.remove(name)
+	}
+
+	return writeFile(path, content, b.String())
+}
+
+// Rename replaces the entry named oldName in the ekalias managed block of
+// the file at path with definition under newName, keeping its place in the
+// block. The file is written, and backed up, once.
+func Rename(path, oldName, newName, definition string) error {
+	content, err := readFile(path)
+	if err != nil {
+		return err
+	}
+
+	b, err := parseBlock(content)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	b.rename(oldName, newName, strings.Split(strings.TrimRight(definition, "\n"), "\n"))
+
+	return writeFile(path, content, b.String())
+}
+
+func readFile(path string) (string, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func writeFile(path, oldContent, newContent string) error {
+	if oldContent == newContent {
+		return nil
+	}
+
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+		if err := ioutil.WriteFile(path+backupExt, []byte(oldContent), mode); err != nil {
+			return err
+		}
+	} else if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, []byte(newContent), mode)
+}
+
+type entry struct {
+	name  string
+	lines []string
+}
+
+type block struct {
+	before  []string
+	entries []entry
+	after   []string
+	found   bool
+}
+
+func parseBlock(content string) (block, error) {
+	var b block
+	if content == "" {
+		return b, nil
+	}
+
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	start := indexOf(lines, blockBegin, 0)
+	if start < 0 {
+		b.before = lines
+		return b, nil
+	}
+	end := indexOf(lines, blockEnd, start+1)
+	if end < 0 {
+		return b, ErrMalformedBlock
+	}
+
+	b.found = true
+	b.before = lines[:start]
+	b.after = lines[end+1:]
+	for _, line := range lines[start+1 : end] {
+		switch {
+		case strings.HasPrefix(line, entryPrefix):
+			b.entries = append(b.entries, entry{name: strings.TrimPrefix(line, entryPrefix)})
+		case len(b.entries) > 0:
+			last := &b.entries[len(b.entries)-1]
+			last.lines = append(last.lines, line)
+		}
+	}
+	return b, nil
+}
+
+func indexOf(lines []string, value string, from int) int {
+	for i := from; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == value {
+			return i
+		}
+	}
+	return -1
+}
+
+func (b *block) set(name string, lines []string) {
+	for i := range b.entries {
+		if b.entries[i].name == name {
+			b.entries[i].lines = lines
+			return
+		}
+	}
+	b.entries = append(b.entries, entry{name: name, lines: lines})
+}
+
+func (b *block) rename(oldName, newName string, lines []string) {
+	for i := range b.entries {
+		if b.entries[i].name == oldName {
+			b.entries[i] = entry{name: newName, lines: lines}
+			return
+		}
+	}
+	b.set(newName, lines)
+}
+
+func (b *block) remove(name string) {
+	for i := range b.entries {
+		if b.entries[i].name == name {
+			b.entries = append(b.entries[:i], b.entries[i+1:]...)
+			return
+		}
+	}
+}
+
+func (b block) String() string {
+	var out []string
+	out = append(out, b.before...)
+	if len(b.entries) > 0 {
+		if !b.found && len(out) > 0 && out[len(out)-1] != "" {
+			out = append(out, "")
+		}
+		out = append(out, blockBegin)
+		for _, e := range b.entries {
+			out = append(out, entryPrefix+e.name)
+			out = append(out, e.lines...)
+		}
+		out = append(out, blockEnd)
+	}
+	out = append(out, b.after...)
+	if len(out) == 0 {
+		return ""
+	}
+	return strings.Join(out, "\n") + "\n"
+}
@@ -0,0 +1,184 @@
+package shell
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type RcSuite struct {
+	suite.Suite
+	dir string
+}
+
+func TestRcSuite(t *testing.T) {
+	suite.Run(t, new(RcSuite))
+}
+
+func (suite *RcSuite) SetupTest() {
+	dir, err := ioutil.TempDir("", "ekalias")
+	suite.Require().NoError(err)
+	suite.dir = dir
+}
+
+func (suite *RcSuite) TearDownTest() {
+	os.RemoveAll(suite.dir)
+}
+
+func (suite *RcSuite) read(path string) string {
+	data, err := ioutil.ReadFile(path)
+	suite.Require().NoError(err)
+	return string(data)
+}
+
+func (suite *RcSuite) TestDetect() {
+	suite.Equal("bash", Detect(""))
+	suite.Equal("zsh", Detect("/usr/bin/zsh"))
+	suite.Equal("bash", Detect("/bin/bash"))
+	suite.Equal("pwsh", Detect("pwsh.exe"))
+}
+
+func (suite *RcSuite) TestRcFile() {
+	os.Unsetenv("ZDOTDIR")
+	os.Unsetenv("XDG_CONFIG_HOME")
+	os.Unsetenv("ENV")
+	cases := []struct {
+		shell    string
+		expected string
+		err      bool
+	}{
+		{shell: "bash", expected: "/home/user/.bashrc"},
+		{shell: "zsh", expected: "/home/user/.zshrc"},
+		{shell: "sh", expected: "/home/user/.profile"},
+		{shell: "dash", expected: "/home/user/.profile"},
+		{shell: "ksh", expected: "/home/user/.kshrc"},
+		{shell: "mksh", expected: "/home/user/.mkshrc"},
+		{shell: "fish", expected: "/home/user/.config/fish/config.fish"},
+		{shell: "pwsh", expected: "/home/user/.config/powershell/Microsoft.PowerShell_profile.ps1"},
+		{shell: "nu", expected: "/home/user/.config/nushell/config.nu"},
+		{shell: "tcsh", err: true},
+	}
+
+	for _, c := range cases {
+		res, err := RcFile(c.shell, "/home/user")
+		if c.err {
+			suite.True(errors.Is(err, ErrUnsupportedShell))
+		} else {
+			suite.NoError(err)
+			suite.Equal(c.expected, res)
+		}
+	}
+
+	os.Setenv("ZDOTDIR", "/home/user/.config/zsh")
+	defer os.Unsetenv("ZDOTDIR")
+	res, err := RcFile("zsh", "/home/user")
+	suite.NoError(err)
+	suite.Equal("/home/user/.config/zsh/.zshrc", res)
+
+	os.Setenv("ENV", "$EKALIAS_TEST_HOME/.shrc")
+	os.Setenv("EKALIAS_TEST_HOME", "/home/user")
+	defer os.Unsetenv("ENV")
+	defer os.Unsetenv("EKALIAS_TEST_HOME")
+	res, err = RcFile("sh", "/home/user")
+	suite.NoError(err)
+	suite.Equal("/home/user/.shrc", res)
+}
+
+func (suite *RcSuite) TestInstallNewFile() {
+	path := filepath.Join(suite.dir, ".bashrc")
+
+	err := Install(path, "dev", `alias dev="x"`)
+	suite.NoError(err)
+	suite.Equal("# >>> ekalias >>>\n# ekalias: dev\nalias dev=\"x\"\n# <<< ekalias <<<\n", suite.read(path))
+	suite.NoFileExists(path + backupExt)
+}
+
+func (suite *RcSuite) TestInstallExistingFile() {
+	path := filepath.Join(suite.dir, ".bashrc")
+	original := "export PATH=$PATH:/opt/bin\n"
+	suite.Require().NoError(ioutil.WriteFile(path, []byte(original), 0600))
+
+	suite.NoError(Install(path, "dev", `alias dev="x"`))
+	suite.Equal(original+"\n# >>> ekalias >>>\n# ekalias: dev\nalias dev=\"x\"\n# <<< ekalias <<<\n", suite.read(path))
+	suite.Equal(original, suite.read(path+backupExt))
+
+	info, err := os.Stat(path)
+	suite.NoError(err)
+	suite.Equal(os.FileMode(0600), info.Mode().Perm())
+}
+
+func (suite *RcSuite) TestInstallUpdatesInPlace() {
+	path := filepath.Join(suite.dir, ".zshrc")
+	content := "before\n# >>> ekalias >>>\n# ekalias: dev\nalias dev=\"x\"\n# ekalias: prod\nalias prod=\"y\"\n# <<< ekalias <<<\nafter\n"
+	suite.Require().NoError(ioutil.WriteFile(path, []byte(content), 0644))
+
+	suite.NoError(Install(path, "dev", `alias dev="z"`))
+	suite.Equal("before\n# >>> ekalias >>>\n# ekalias: dev\nalias dev=\"z\"\n# ekalias: prod\nalias prod=\"y\"\n# <<< ekalias <<<\nafter\n", suite.read(path))
+	suite.Equal(content, suite.read(path+backupExt))
+
+	suite.NoError(Install(path, "stage", `alias stage="w"`))
+	suite.Equal("before\n# >>> ekalias >>>\n# ekalias: dev\nalias dev=\"z\"\n# ekalias: prod\nalias prod=\"y\"\n# ekalias: stage\nalias stage=\"w\"\n# <<< ekalias <<<\nafter\n", suite.read(path))
+}
+
+func (suite *RcSuite) TestInstallAll() {
+	path := filepath.Join(suite.dir, ".bashrc")
+	content := "before\n# >>> ekalias >>>\n# ekalias: dev\nalias dev=\"x\"\n# <<< ekalias <<<\n"
+	suite.Require().NoError(ioutil.WriteFile(path, []byte(content), 0644))
+
+	suite.NoError(InstallAll(path, []Definition{
+		{Name: "dev", Text: `alias dev="z"`},
+		{Name: "prod", Text: `alias prod="y"`},
+	}))
+	suite.Equal("before\n# >>> ekalias >>>\n# ekalias: dev\nalias dev=\"z\"\n# ekalias: prod\nalias prod=\"y\"\n# <<< ekalias <<<\n", suite.read(path))
+	suite.Equal(content, suite.read(path+backupExt))
+}
+
+func (suite *RcSuite) TestInstallUnchanged() {
+	path := filepath.Join(suite.dir, ".bashrc")
+	suite.NoError(Install(path, "dev", `alias dev="x"`))
+	suite.NoError(Install(path, "dev", `alias dev="x"`))
+	suite.NoFileExists(path + backupExt)
+}
+
+func (suite *RcSuite) TestInstallMalformedBlock() {
+	path := filepath.Join(suite.dir, ".bashrc")
+	content := "# >>> ekalias >>>\nalias dev=\"x\"\n"
+	suite.Require().NoError(ioutil.WriteFile(path, []byte(content), 0644))
+
+	err := Install(path, "dev", `alias dev="y"`)
+	suite.True(errors.Is(err, ErrMalformedBlock))
+	suite.Equal(content, suite.read(path))
+}
+
+func (suite *RcSuite) TestUninstall() {
+	path := filepath.Join(suite.dir, ".bashrc")
+	content := "before\n# >>> ekalias >>>\n# ekalias: dev\nalias dev=\"x\"\n# ekalias: prod\nalias prod=\"y\"\n# <<< ekalias <<<\nafter\n"
+	suite.Require().NoError(ioutil.WriteFile(path, []byte(content), 0644))
+
+	suite.NoError(Uninstall(path, "dev"))
+	suite.Equal("before\n# >>> ekalias >>>\n# ekalias: prod\nalias prod=\"y\"\n# <<< ekalias <<<\nafter\n", suite.read(path))
+	suite.Equal(content, suite.read(path+backupExt))
+
+	suite.NoError(Uninstall(path, "prod", "missing"))
+	suite.Equal("before\nafter\n", suite.read(path))
+
+	suite.NoError(Uninstall(filepath.Join(suite.dir, "missing"), "dev"))
+	suite.NoFileExists(filepath.Join(suite.dir, "missing"))
+}
+
+func (suite *RcSuite) TestRename() {
+	path := filepath.Join(suite.dir, ".bashrc")
+	content := "before\n# >>> ekalias >>>\n# ekalias: dev\nalias dev=\"x\"\n# ekalias: prod\nalias prod=\"y\"\n# <<< ekalias <<<\nafter\n"
+	suite.Require().NoError(ioutil.WriteFile(path, []byte(content), 0644))
+
+	suite.NoError(Rename(path, "dev", "stage", `alias stage="x"`))
+	suite.Equal("before\n# >>> ekalias >>>\n# ekalias: stage\nalias stage=\"x\"\n# ekalias: prod\nalias prod=\"y\"\n# <<< ekalias <<<\nafter\n", suite.read(path))
+	suite.Equal(content, suite.read(path+backupExt))
+
+	suite.NoError(Rename(path, "missing", "qa", `alias qa="z"`))
+	suite.Equal("before\n# >>> ekalias >>>\n# ekalias: stage\nalias stage=\"x\"\n# ekalias: prod\nalias prod=\"y\"\n# ekalias: qa\nalias qa=\"z\"\n# <<< ekalias <<<\nafter\n", suite.read(path))
+}
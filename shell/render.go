@@ -0,0 +1,123 @@
+package shell
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Alias is what an alias switches to. With Kubeconfig set, the alias points
+// KUBECONFIG at that file instead of changing the shared current context, and
+// the context and namespace are expected to be set in the file.
+type Alias struct {
+	Name       string
+	Profile    string
+	Context    string
+	Namespace  string
+	Kubeconfig string
+}
+
+type Renderer interface {
+	Render(Alias) string
+	// Validate reports whether name can be used as an alias in the shell.
+	Validate(name string) error
+}
+
+var renderers = map[string]Renderer{
+	"bash":       Posix{},
+	"zsh":        Posix{},
+	"sh":         Posix{},
+	"dash":       Posix{},
+	"ksh":        Posix{},
+	"mksh":       Posix{},
+	"fish":       Fish{},
+	"pwsh":       PowerShell{},
+	"powershell": PowerShell{},
+	"nu":         Nushell{},
+}
+
+func NewRenderer(shell string) (Renderer, error) {
+	r, ok := renderers[shell]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s (supported: %s)", ErrUnsupportedShell, shell, strings.Join(Shells(), ", "))
+	}
+	return r, nil
+}
+
+func Shells() []string {
+	var res []string
+	for name := range renderers {
+		res = append(res, name)
+	}
+	sort.Strings(res)
+	return res
+}
+
+type Posix struct{}
+
+func (Posix) Render(a Alias) string {
+	if a.Kubeconfig != "" {
+		body := fmt.Sprintf("export AWS_PROFILE=%s KUBECONFIG=%s", posixQuote(a.Profile), posixQuote(a.Kubeconfig))
+		return fmt.Sprintf("alias %s=%s", a.Name, posixAliasBody(body))
+	}
+	body := fmt.Sprintf("export AWS_PROFILE=%s && kubectl config use-context %s", posixQuote(a.Profile), posixQuote(a.Context))
+	if a.Namespace != "" {
+		body += " && kubectl config set-context --current --namespace=" + posixQuote(a.Namespace)
+	}
+	return fmt.Sprintf("alias %s=%s", a.Name, posixAliasBody(body))
+}
+
+func (Posix) Validate(name string) error {
+	return validateName(name, "posix shells", posixName)
+}
+
+type Fish struct{}
+
+func (Fish) Render(a Alias) string {
+	if a.Kubeconfig != "" {
+		return fmt.Sprintf("function %s\n    set -gx AWS_PROFILE %s\n    set -gx KUBECONFIG %s\nend", a.Name, fishQuote(a.Profile), fishQuote(a.Kubeconfig))
+	}
+	ns := ""
+	if a.Namespace != "" {
+		ns = "\n    kubectl config set-context --current --namespace=" + fishQuote(a.Namespace)
+	}
+	return fmt.Sprintf("function %s\n    set -gx AWS_PROFILE %s\n    kubectl config use-context %s%s\nend", a.Name, fishQuote(a.Profile), fishQuote(a.Context), ns)
+}
+
+func (Fish) Validate(name string) error {
+	return validateName(name, "fish", posixName)
+}
+
+type PowerShell struct{}
+
+func (PowerShell) Render(a Alias) string {
+	if a.Kubeconfig != "" {
+		return fmt.Sprintf("function %s {\n    $env:AWS_PROFILE = %s\n    $env:KUBECONFIG = %s\n}", a.Name, powerShellQuote(a.Profile), powerShellQuote(a.Kubeconfig))
+	}
+	ns := ""
+	if a.Namespace != "" {
+		ns = "\n    kubectl config set-context --current --namespace " + powerShellQuote(a.Namespace)
+	}
+	return fmt.Sprintf("function %s {\n    $env:AWS_PROFILE = %s\n    kubectl config use-context %s%s\n}", a.Name, powerShellQuote(a.Profile), powerShellQuote(a.Context), ns)
+}
+
+func (PowerShell) Validate(name string) error {
+	return validateName(name, "powershell", identifierName)
+}
+
+type Nushell struct{}
+
+func (Nushell) Render(a Alias) string {
+	if a.Kubeconfig != "" {
+		return fmt.Sprintf("def --env %s [] {\n    $env.AWS_PROFILE = %s\n    $env.KUBECONFIG = %s\n}", a.Name, nushellQuote(a.Profile), nushellQuote(a.Kubeconfig))
+	}
+	ns := ""
+	if a.Namespace != "" {
+		ns = "\n    ^kubectl config set-context --current --namespace " + nushellQuote(a.Namespace)
+	}
+	return fmt.Sprintf("def --env %s [] {\n    $env.AWS_PROFILE = %s\n    ^kubectl config use-context %s%s\n}", a.Name, nushellQuote(a.Profile), nushellQuote(a.Context), ns)
+}
+
+func (Nushell) Validate(name string) error {
+	return validateName(name, "nushell", identifierName)
+}
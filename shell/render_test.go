@@ -0,0 +1,93 @@
+package shell
+
+import (
+	"errors"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+type RenderSuite struct {
+	suite.Suite
+}
+
+func TestRenderSuite(t *testing.T) {
+	suite.Run(t, new(RenderSuite))
+}
+
+func (suite RenderSuite) golden(name, actual string) {
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		suite.Require().NoError(ioutil.WriteFile(path, []byte(actual), 0644))
+	}
+	expected, err := ioutil.ReadFile(path)
+	suite.Require().NoError(err)
+	suite.Equal(string(expected), actual)
+}
+
+func (suite RenderSuite) TestRenderers() {
+	a := Alias{Name: "prod", Profile: "prod-admin", Context: "arn:aws:eks:us-east-1:123456789012:cluster/prod"}
+
+	for _, shell := range []string{"bash", "fish", "pwsh", "nu"} {
+		r, err := NewRenderer(shell)
+		suite.Require().NoError(err)
+		suite.golden(shell, r.Render(a)+"\n")
+	}
+}
+
+func (suite RenderSuite) TestRenderersQuoting() {
+	a := Alias{Name: "dev", Profile: "it's $(whoami)", Context: "`id` \"dev\" \\ ‘x’"}
+
+	for _, shell := range []string{"bash", "fish", "pwsh", "nu"} {
+		r, err := NewRenderer(shell)
+		suite.Require().NoError(err)
+		suite.golden(shell+"-quoted", r.Render(a)+"\n")
+	}
+}
+
+func (suite RenderSuite) TestRenderersIsolated() {
+	a := Alias{Name: "prod", Profile: "prod-admin", Context: "prod", Kubeconfig: "/home/me/.config/ekalias/kube/prod.yaml"}
+
+	for _, shell := range []string{"bash", "fish", "pwsh", "nu"} {
+		r, err := NewRenderer(shell)
+		suite.Require().NoError(err)
+		suite.golden(shell+"-isolated", r.Render(a)+"\n")
+	}
+}
+
+func (suite RenderSuite) TestRenderersNamespace() {
+	a := Alias{Name: "prod", Profile: "prod-admin", Context: "prod", Namespace: "payments"}
+
+	for _, shell := range []string{"bash", "fish", "pwsh", "nu"} {
+		r, err := NewRenderer(shell)
+		suite.Require().NoError(err)
+		suite.golden(shell+"-namespace", r.Render(a)+"\n")
+	}
+}
+
+func (suite RenderSuite) TestNewRenderer() {
+	r, err := NewRenderer("zsh")
+	suite.NoError(err)
+	suite.Equal(Posix{}, r)
+
+	r, err = NewRenderer("ksh")
+	suite.NoError(err)
+	suite.Equal(Posix{}, r)
+
+	r, err = NewRenderer("powershell")
+	suite.NoError(err)
+	suite.Equal(PowerShell{}, r)
+
+	r, err = NewRenderer("tcsh")
+	suite.True(errors.Is(err, ErrUnsupportedShell))
+	suite.Nil(r)
+}
+
+func (suite RenderSuite) TestShells() {
+	suite.Equal([]string{"bash", "dash", "fish", "ksh", "mksh", "nu", "powershell", "pwsh", "sh", "zsh"}, Shells())
+}
@@ -0,0 +1,76 @@
+package sso
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var ErrNoToken = errors.New("no valid sso token found, run `aws sso login` first")
+
+// Token is an access token cached by `aws sso login`.
+type Token struct {
+	AccessToken string `json:"accessToken"`
+	ExpiresAt   string `json:"expiresAt"`
+	Region      string `json:"region"`
+	StartURL    string `json:"startUrl"`
+}
+
+// CacheDir returns the directory the aws cli caches sso tokens in.
+func CacheDir() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".aws", "sso", "cache")
+}
+
+// Expires parses ExpiresAt, which older cli versions write as 2006-01-02T15:04:05UTC.
+func (t Token) Expires() (time.Time, error) {
+	if e, err := time.Parse(time.RFC3339, t.ExpiresAt); err == nil {
+		return e, nil
+	}
+	return time.Parse("2006-01-02T15:04:05UTC", t.ExpiresAt)
+}
+
+// Valid reports whether the token can still be used at now.
+func (t Token) Valid(now time.Time) bool {
+	expires, err := t.Expires()
+	return t.AccessToken != "" && err == nil && now.Before(expires)
+}
+
+// CachedToken returns the unexpired token for startURL from the cache in dir.
+// Tokens are cached by session name or by start url depending on how the
+// profile was configured, so every file in dir is checked.
+func CachedToken(dir, startURL string, now time.Time) (Token, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return Token{}, err
+	}
+
+	var found Token
+	for _, f := range files {
+		data, err := ioutil.ReadFile(f)
+		if err != nil {
+			continue
+		}
+		var t Token
+		if json.Unmarshal(data, &t) != nil || !sameURL(t.StartURL, startURL) || !t.Valid(now) {
+			continue
+		}
+		if found.AccessToken == "" || t.ExpiresAt > found.ExpiresAt {
+			found = t
+		}
+	}
+
+	if found.AccessToken == "" {
+		return Token{}, fmt.Errorf("%w for %s", ErrNoToken, startURL)
+	}
+	return found, nil
+}
+
+func sameURL(a, b string) bool {
+	return strings.TrimSuffix(a, "/") == strings.TrimSuffix(b, "/")
+}
@@ -0,0 +1,54 @@
+package sso
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type CacheSuite struct {
+	suite.Suite
+}
+
+func TestCacheSuite(t *testing.T) {
+	suite.Run(t, new(CacheSuite))
+}
+
+var cacheDir = filepath.Join("testdata", "cache")
+
+func (suite CacheSuite) TestExpires() {
+	e, err := Token{ExpiresAt: "2030-01-02T03:04:05Z"}.Expires()
+	suite.NoError(err)
+	suite.Equal(time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC), e.UTC())
+
+	e, err = Token{ExpiresAt: "2020-01-02T03:04:05UTC"}.Expires()
+	suite.NoError(err)
+	suite.Equal(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC), e.UTC())
+
+	_, err = Token{ExpiresAt: "tomorrow"}.Expires()
+	suite.Error(err)
+}
+
+func (suite CacheSuite) TestCachedToken() {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t, err := CachedToken(cacheDir, "https://corp.awsapps.com/start/", now)
+	suite.NoError(err)
+	suite.Equal("valid-token", t.AccessToken)
+
+	t, err = CachedToken(cacheDir, "https://other.awsapps.com/start", now)
+	suite.NoError(err)
+	suite.Equal("other-token", t.AccessToken)
+
+	_, err = CachedToken(cacheDir, "https://corp.awsapps.com/start", time.Date(2031, 1, 1, 0, 0, 0, 0, time.UTC))
+	suite.True(errors.Is(err, ErrNoToken))
+
+	_, err = CachedToken(cacheDir, "https://unknown.awsapps.com/start", now)
+	suite.True(errors.Is(err, ErrNoToken))
+
+	_, err = CachedToken(filepath.Join("testdata", "missing"), "https://corp.awsapps.com/start", now)
+	suite.True(errors.Is(err, ErrNoToken))
+}
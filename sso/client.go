@@ -0,0 +1,156 @@
+package sso
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/eiladin/ekalias/console"
+)
+
+type Account struct {
+	ID    string `json:"accountId"`
+	Name  string `json:"accountName"`
+	Email string `json:"emailAddress"`
+}
+
+type Role struct {
+	AccountID string `json:"accountId"`
+	Name      string `json:"roleName"`
+}
+
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e APIError) Error() string {
+	return fmt.Sprintf("sso: %d %s", e.StatusCode, e.Message)
+}
+
+// Client calls the AWS SSO portal API with a cached access token. Endpoint
+// overrides the regional https://portal.sso.<region>.amazonaws.com endpoint.
+// Timeout limits how long each request may take.
+type Client struct {
+	Region      string
+	AccessToken string
+	Endpoint    string
+	HTTPClient  *http.Client
+	Timeout     time.Duration
+	ctx         context.Context
+}
+
+// WithContext returns a client whose requests are stopped when ctx is done.
+func (c Client) WithContext(ctx context.Context) Client {
+	c.ctx = ctx
+	return c
+}
+
+func (c Client) endpoint() string {
+	if c.Endpoint != "" {
+		return strings.TrimSuffix(c.Endpoint, "/")
+	}
+	suffix := "amazonaws.com"
+	if strings.HasPrefix(c.Region, "cn-") {
+		suffix = "amazonaws.com.cn"
+	}
+	return fmt.Sprintf("https://portal.sso.%s.%s", c.Region, suffix)
+}
+
+// Accounts lists every account the token has access to.
+func (c Client) Accounts() ([]Account, error) {
+	var accounts []Account
+	err := c.paginate("/assignment/accounts", url.Values{}, func(body []byte) (string, error) {
+		var res struct {
+			AccountList []Account `json:"accountList"`
+			NextToken   string    `json:"nextToken"`
+		}
+		err := json.Unmarshal(body, &res)
+		accounts = append(accounts, res.AccountList...)
+		return res.NextToken, err
+	})
+	return accounts, err
+}
+
+// Roles lists the roles the token can assume in an account.
+func (c Client) Roles(accountID string) ([]Role, error) {
+	var roles []Role
+	q := url.Values{}
+	q.Set("account_id", accountID)
+	err := c.paginate("/assignment/roles", q, func(body []byte) (string, error) {
+		var res struct {
+			RoleList  []Role `json:"roleList"`
+			NextToken string `json:"nextToken"`
+		}
+		err := json.Unmarshal(body, &res)
+		roles = append(roles, res.RoleList...)
+		return res.NextToken, err
+	})
+	return roles, err
+}
+
+func (c Client) paginate(path string, q url.Values, page func(body []byte) (string, error)) error {
+	q.Set("max_result", "100")
+	for {
+		body, err := c.get(path, q)
+		if err != nil {
+			return err
+		}
+		next, err := page(body)
+		if err != nil || next == "" {
+			return err
+		}
+		q.Set("next_token", next)
+	}
+}
+
+func (c Client) get(path string, q url.Values) ([]byte, error) {
+	ctx := c.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.endpoint()+path+"?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("x-amz-sso_bearer_token", c.AccessToken)
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, console.RequestError(ctx, err, req)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, console.RequestError(ctx, err, req)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		apiErr := APIError{StatusCode: resp.StatusCode}
+		var msg struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(body, &msg) == nil && msg.Message != "" {
+			apiErr.Message = msg.Message
+		} else {
+			apiErr.Message = strings.TrimSpace(string(body))
+		}
+		return nil, apiErr
+	}
+	return body, nil
+}
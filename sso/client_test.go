@@ -0,0 +1,113 @@
+package sso
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/eiladin/ekalias/console"
+	"github.com/stretchr/testify/suite"
+)
+
+type ClientSuite struct {
+	suite.Suite
+}
+
+func TestClientSuite(t *testing.T) {
+	suite.Run(t, new(ClientSuite))
+}
+
+func stubServer(handler http.HandlerFunc) (*httptest.Server, Client) {
+	srv := httptest.NewServer(handler)
+	return srv, Client{Region: "us-east-2", AccessToken: "token", Endpoint: srv.URL}
+}
+
+func (suite ClientSuite) TestEndpoint() {
+	suite.Equal("https://portal.sso.us-east-2.amazonaws.com", Client{Region: "us-east-2"}.endpoint())
+	suite.Equal("https://portal.sso.cn-north-1.amazonaws.com.cn", Client{Region: "cn-north-1"}.endpoint())
+	suite.Equal("http://localhost:1234", Client{Region: "us-east-2", Endpoint: "http://localhost:1234/"}.endpoint())
+}
+
+func (suite ClientSuite) TestAccounts() {
+	srv, c := stubServer(func(w http.ResponseWriter, r *http.Request) {
+		suite.Equal("/assignment/accounts", r.URL.Path)
+		suite.Equal("token", r.Header.Get("x-amz-sso_bearer_token"))
+		suite.Equal("100", r.URL.Query().Get("max_result"))
+		switch r.URL.Query().Get("next_token") {
+		case "":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"accountList": []map[string]string{{"accountId": "111111111111", "accountName": "dev", "emailAddress": "dev@example.com"}},
+				"nextToken":   "page2",
+			})
+		case "page2":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"accountList": []map[string]string{{"accountId": "222222222222", "accountName": "prod"}},
+			})
+		}
+	})
+	defer srv.Close()
+
+	accounts, err := c.Accounts()
+	suite.NoError(err)
+	suite.Equal([]Account{
+		{ID: "111111111111", Name: "dev", Email: "dev@example.com"},
+		{ID: "222222222222", Name: "prod"},
+	}, accounts)
+}
+
+func (suite ClientSuite) TestRoles() {
+	srv, c := stubServer(func(w http.ResponseWriter, r *http.Request) {
+		suite.Equal("/assignment/roles", r.URL.Path)
+		suite.Equal("111111111111", r.URL.Query().Get("account_id"))
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"roleList": []map[string]string{
+				{"accountId": "111111111111", "roleName": "AdministratorAccess"},
+				{"accountId": "111111111111", "roleName": "ReadOnly"},
+			},
+		})
+	})
+	defer srv.Close()
+
+	roles, err := c.Roles("111111111111")
+	suite.NoError(err)
+	suite.Equal([]Role{
+		{AccountID: "111111111111", Name: "AdministratorAccess"},
+		{AccountID: "111111111111", Name: "ReadOnly"},
+	}, roles)
+}
+
+func (suite ClientSuite) TestAPIError() {
+	srv, c := stubServer(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"message":"Session token not found or invalid"}`))
+	})
+	defer srv.Close()
+
+	_, err := c.Accounts()
+	var apiErr APIError
+	suite.True(errors.As(err, &apiErr))
+	suite.Equal(http.StatusUnauthorized, apiErr.StatusCode)
+	suite.Equal("sso: 401 Session token not found or invalid", err.Error())
+}
+
+func (suite ClientSuite) TestStopped() {
+	srv, c := stubServer(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	})
+	defer srv.Close()
+
+	c.Timeout = 50 * time.Millisecond
+	_, err := c.Accounts()
+	suite.True(errors.Is(err, console.ErrTimeout), err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	c.Timeout = 0
+	c = c.WithContext(ctx)
+	_, err = c.Accounts()
+	suite.True(errors.Is(err, console.ErrCancelled), err)
+}